@@ -0,0 +1,68 @@
+package main
+
+import (
+	"apartmenthunter/internal/archive"
+	"apartmenthunter/internal/config"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// runArchiveQuery implements the "archive" subcommand, e.g.
+//
+//	apartmenthunter archive -company Gewobag -district Neukölln -rooms 2 -max-price 900 -from 2026-09-01 -to 2026-09-30
+func runArchiveQuery(args []string) error {
+	flags := flag.NewFlagSet("archive", flag.ContinueOnError)
+	var q archive.Query
+	var rooms float64
+	var from, to string
+	flags.StringVar(&q.Company, "company", "", "company, e.g. Gewobag")
+	flags.StringVar(&q.ZipCode, "zip", "", "zip code")
+	flags.StringVar(&q.District, "district", "", "Bezirk or Ortsteil, e.g. Neukölln")
+	flags.Float64Var(&q.MinPrice, "min-price", 0, "minimum warm rent in €")
+	flags.Float64Var(&q.MaxPrice, "max-price", 0, "maximum warm rent in €")
+	flags.Float64Var(&q.MinSqm, "min-sqm", 0, "minimum size in m²")
+	flags.Float64Var(&q.MaxSqm, "max-sqm", 0, "maximum size in m²")
+	flags.Float64Var(&rooms, "rooms", 0, "exact number of rooms")
+	flags.Float64Var(&q.MinRooms, "min-rooms", 0, "minimum number of rooms")
+	flags.Float64Var(&q.MaxRooms, "max-rooms", 0, "maximum number of rooms")
+	flags.StringVar(&from, "from", "", "first seen on or after this date (YYYY-MM-DD)")
+	flags.StringVar(&to, "to", "", "first seen on or before this date (YYYY-MM-DD)")
+	format := flags.String("format", archive.FormatTable, "output format: table, csv or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if rooms > 0 {
+		q.MinRooms, q.MaxRooms = rooms, rooms
+	}
+	location := loadLocation()
+	var err error
+	if q.From, err = parseDate(from, location); err != nil {
+		return err
+	}
+	if q.To, err = parseDate(to, location); err != nil {
+		return err
+	}
+	if !q.To.IsZero() {
+		q.To = q.To.AddDate(0, 0, 1) // include the whole day
+	}
+
+	records, err := archive.New(config.ArchiveFile).Query(q)
+	if err != nil {
+		return err
+	}
+	return archive.Write(os.Stdout, records, *format, location)
+}
+
+func parseDate(value string, location *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD", value)
+	}
+	return date, nil
+}
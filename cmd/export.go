@@ -0,0 +1,125 @@
+package main
+
+import (
+	"apartmenthunter/internal/archive"
+	"apartmenthunter/internal/config"
+	"apartmenthunter/internal/notify"
+	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/users"
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// matchRecorder stores the notification status of matches in the archive
+type matchRecorder struct {
+	archive *archive.Archive
+}
+
+func (r matchRecorder) RecordNotification(userID string, listing common.Listing, status notify.Status) {
+	if err := r.archive.RecordMatch(userID, listing, string(status), time.Now()); err != nil {
+		log.Printf("[%s] error archiving match for user %q: %v", listing.ID, userID, err)
+	}
+}
+
+// runExport implements the "export" subcommand, e.g.
+//
+//	apartmenthunter export -user alice -format csv -out applications.csv
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	userID := flags.String("user", "", "user ID, all users if empty")
+	format := flags.String("format", archive.FormatCSV, "output format: csv or jsonl")
+	out := flags.String("out", "", "output file, stdout if empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	userIDs := []string{*userID}
+	if *userID == "" {
+		userIDs = allUserIDs(users.LoadFromStaticConfig())
+	}
+	var matches []archive.Match
+	for _, id := range userIDs {
+		userMatches, err := archive.New(config.ArchiveFile).Matches(id)
+		if err != nil {
+			return err
+		}
+		matches = append(matches, userMatches...)
+	}
+	return archive.WriteMatches(w, matches, *format)
+}
+
+// startExporter periodically writes the matches of every user to config.ExportDir
+func startExporter(ctx context.Context, listingArchive *archive.Archive, allUsers *users.FilterConfig) {
+	if config.ExportDir == "" {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(config.ExportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, userID := range allUserIDs(allUsers) {
+					if err := exportMatches(listingArchive, userID); err != nil {
+						log.Printf("error exporting matches of user %q: %v", userID, err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// exportMatches writes <user>.csv and <user>.jsonl, replacing the previous export. The
+// default user without an ID is exported as default.csv and default.jsonl.
+func exportMatches(listingArchive *archive.Archive, userID string) error {
+	matches, err := listingArchive.Matches(userID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(config.ExportDir, 0o755); err != nil {
+		return err
+	}
+	for _, format := range []string{archive.FormatCSV, archive.FormatJSONL} {
+		path := filepath.Join(config.ExportDir, archive.UserKey(userID)+"."+format)
+		f, err := os.Create(path + ".tmp")
+		if err != nil {
+			return err
+		}
+		err = archive.WriteMatches(f, matches, format)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func allUserIDs(filterConfig *users.FilterConfig) []string {
+	var ids []string
+	for _, user := range filterConfig.Users {
+		ids = append(ids, user.UserID)
+	}
+	return ids
+}
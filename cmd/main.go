@@ -1,20 +1,39 @@
 package main
 
 import (
+	"apartmenthunter/internal/admin"
+	"apartmenthunter/internal/archive"
 	"apartmenthunter/internal/bot"
 	"apartmenthunter/internal/config"
+	"apartmenthunter/internal/dedup"
+	"apartmenthunter/internal/digest"
+	"apartmenthunter/internal/geo"
+	"apartmenthunter/internal/health"
 	"apartmenthunter/internal/http"
+	"apartmenthunter/internal/metrics"
+	"apartmenthunter/internal/notify"
 	"apartmenthunter/internal/scraping/common"
 	"apartmenthunter/internal/scraping/factory"
+	"apartmenthunter/internal/stats"
 	"apartmenthunter/internal/store"
 	"apartmenthunter/internal/telegram"
+	"apartmenthunter/internal/transit"
 	"apartmenthunter/internal/users"
 	"context"
+	"errors"
+	"fmt"
 	"github.com/joho/godotenv"
+	"html"
 	"log"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
+	_ "time/tzdata" // the runtime image has no zoneinfo
 )
 
 var scrapersTypes = []string{
@@ -25,6 +44,15 @@ var scrapersTypes = []string{
 	"WBM",
 }
 
+// companyURLs are the search pages of the scrapers, used to find their hosts
+var companyURLs = map[string]string{
+	"Howoge":       config.HowogeURL,
+	"Dewego":       config.DewegoURL,
+	"Gewobag":      config.GewobagURL,
+	"StadtUndLand": config.StadtUndLandURL,
+	"WBM":          config.WbmURL,
+}
+
 func init() {
 	pwd, _ := os.Getwd()
 	log.Printf("Current working directory: %s\n", pwd)
@@ -36,6 +64,24 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "stats":
+			fmt.Print(stats.Text(companyStats(loadStates(), loadLocation())))
+			return
+		case "archive":
+			if err := runArchiveQuery(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "export":
+			if err := runExport(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	log.Println("starting apartment project")
 
 	telegramClient, err := telegram.NewClient(config.BaseURL, os.Getenv("TELEGRAM_BOT_TOKEN"), os.Getenv("TELEGRAM_CHAT_ID"))
@@ -43,27 +89,282 @@ func main() {
 		log.Fatalf("error initializing telegram client: %v", err)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	htmlMsg := "<b>Apartment Hunter</b> is <i>running...</i>"
-	if err := telegramClient.SendStartup(ctx, htmlMsg); err != nil {
-		log.Fatalf("error sending startup message: %v", err)
+	adminNotifier := admin.NewNotifier(telegramClient, os.Getenv("TELEGRAM_ADMIN_CHAT_ID"),
+		config.AdminDedupWindow, config.AdminMaxAlerts, config.AdminThrottleWindow)
+	adminNotifier.Notify(ctx, "startup", "<b>Apartment Hunter</b> is <i>running...</i>")
+	// listings and digests, failed deliveries are reported to the admins
+	notifyClient := admin.ReportErrors(telegramClient, adminNotifier)
+
+	scraperMetrics := metrics.New()
+	if config.MetricsAddr != "" {
+		go func() {
+			if err := scraperMetrics.Serve(ctx, config.MetricsAddr); err != nil {
+				log.Printf("error serving metrics: %v", err)
+			}
+		}()
+	}
+
+	httpClient := http.NewClient(5*time.Second,
+		http.WithRetry(http.RetryPolicy{
+			MaxAttempts: config.RetryAttempts,
+			BaseDelay:   config.RetryBaseDelay,
+			MaxDelay:    config.RetryMaxDelay,
+			RetryPOST:   config.RetryPOST,
+		}),
+		http.WithCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
+		http.WithCircuitListener(func(host string, state http.CircuitState) {
+			scraperMetrics.ObserveCircuit(host, state)
+			if state != http.CircuitHalfOpen {
+				// sent in the background, the request waits for the listener
+				go adminNotifier.Notify(ctx, "circuit "+host+" "+state.String(), fmt.Sprintf("🔌 Circuit for <b>%s</b> is %s", host, state))
+			}
+		}),
+		http.WithRateLimit(http.RateLimit{Interval: config.RateLimitInterval, Burst: config.RateLimitBurst}, companyRateLimits()),
+		http.WithConditionalRequests(),
+	)
+	monitor := health.NewMonitor(adminNotifier, config.HealthSnippetDir, health.Thresholds{
+		EmptyScrapes:      config.HealthEmptyScrapes,
+		MissingFieldRatio: config.HealthMissingFieldRatio,
+		MinListings:       config.HealthMinListings,
+		SnippetBytes:      config.HealthSnippetBytes,
+	})
+	factoryOpts := []factory.Option{
+		factory.WithSessions(httpClient, config.SessionLandingPages),
+		factory.WithClientWrapper(func(name string, client http.HTTPClient) http.HTTPClient {
+			return scraperMetrics.Wrap(name, monitor.Wrap(name, client))
+		}),
+	}
+	if pool := loadProxyPool(ctx); pool != nil {
+		factoryOpts = append(factoryOpts, factory.WithProxies(httpClient, pool))
+	}
+	if geocoder, err := geo.LoadGeocoder(config.GeocodeDataFile); err != nil {
+		log.Printf("geocoding disabled: %v", err)
+	} else {
+		log.Printf("loaded %d addresses for geocoding", geocoder.Size())
+		factoryOpts = append(factoryOpts, factory.WithGeocoder(geocoder))
+	}
+	if estimator := loadCommuteEstimator(users.LoadFromStaticConfig()); estimator != nil {
+		factoryOpts = append(factoryOpts, factory.WithCommuteEstimator(estimator))
+	}
+	scraperFactory := factory.NewScraperFactory(httpClient, factoryOpts...)
+
+	location := loadLocation()
+
+	history := loadHistory()
+	startDigestScheduler(ctx, history, notifyClient, location)
+
+	listingArchive := archive.New(config.ArchiveFile)
+	startExporter(ctx, listingArchive, users.LoadFromStaticConfig())
+
+	dispatcher := notify.NewDispatcher(notifyClient, location)
+	dispatcher.Recorder = notify.Recorders{matchRecorder{listingArchive}, scraperMetrics}
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(ctx)
+	}()
+
+	states := loadStates()
+	go telegramClient.ListenCommands(ctx, commandChats(telegramClient.ChatID, os.Getenv("TELEGRAM_ADMIN_CHAT_ID")), map[string]telegram.CommandHandler{
+		"stats": func(context.Context, string) string {
+			return stats.HTML(companyStats(states, location))
+		},
+	})
+
+	duplicates := dedup.NewDetector(config.DuplicateWindow)
+	startAllScrapers(ctx, scraperFactory, states, dispatcher, history, duplicates, listingArchive, monitor, adminNotifier, scraperMetrics)
+
+	<-ctx.Done()
+	log.Println("shutting down")
+	<-dispatcherDone // queued listings are delivered before exiting
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelShutdown()
+	adminNotifier.Notify(shutdownCtx, "shutdown", "<b>Apartment Hunter</b> is <i>shutting down</i>")
+}
+
+// commandChats returns the chats allowed to send bot commands, the default and admin chats
+// and the chats of all configured users
+func commandChats(defaultChat, adminChat string) []string {
+	chats := []string{defaultChat}
+	if adminChat != "" {
+		chats = append(chats, adminChat)
+	}
+	for _, user := range users.LoadFromStaticConfig().Users {
+		if user.ChatID != "" {
+			chats = append(chats, user.ChatID)
+		}
+	}
+	return chats
+}
+
+// loadProxyPool creates the pool from the comma separated PROXY_URLS and starts its
+// health checks, returns nil if no proxies are configured
+func loadProxyPool(ctx context.Context) *http.ProxyPool {
+	proxyURLs := strings.FieldsFunc(os.Getenv("PROXY_URLS"), func(r rune) bool { return r == ',' || r == ' ' })
+	if len(proxyURLs) == 0 {
+		return nil
+	}
+	pool, err := http.NewProxyPool(proxyURLs, config.ProxyQuarantine)
+	if err != nil {
+		log.Fatalf("error initializing proxy pool: %v", err)
+	}
+	pool.AllowDirect = config.ProxyAllowDirect
+	log.Printf("sending requests through %d proxies", len(proxyURLs))
+	go pool.Run(ctx, config.ProxyCheckURL, config.ProxyCheckInterval)
+	return pool
+}
+
+// loadCommuteEstimator routes from listings to the users' commute destinations, returns nil
+// if no user has a commute or the GTFS feed cannot be loaded
+func loadCommuteEstimator(filterConfig *users.FilterConfig) *transit.Estimator {
+	var destinations []geo.Point
+	for _, user := range filterConfig.Users {
+		for _, commute := range user.Commutes {
+			destinations = append(destinations, commute.Destination)
+		}
+	}
+	if len(destinations) == 0 {
+		return nil
+	}
+
+	feed, err := transit.LoadGTFS(config.GTFSDir, config.CommuteWeekday)
+	if err != nil {
+		log.Printf("commute estimates disabled: %v", err)
+		return nil
+	}
+	log.Printf("loaded %d stops and %d connections for commute estimates", len(feed.Stops), feed.Connections())
+
+	estimator, err := transit.NewEstimator(transit.NewRouter(feed), config.CommuteDeparture, destinations)
+	if err != nil {
+		log.Printf("commute estimates disabled: %v", err)
+		return nil
+	}
+	return estimator
+}
+
+func startDigestScheduler(ctx context.Context, history *digest.History, client digest.Sender, location *time.Location) {
+	scheduler, err := digest.NewScheduler(history, users.LoadFromStaticConfig(), client, config.DigestTime, config.DigestWeeklyDay, location)
+	if err != nil {
+		log.Fatalf("error initializing digest scheduler: %v", err)
+	}
+	go scheduler.Run(ctx)
+}
+
+// companyRateLimits maps the per company rate limits from the config to their hosts
+func companyRateLimits() map[string]http.RateLimit {
+	limits := map[string]http.RateLimit{}
+	for company, interval := range config.RateLimitIntervals {
+		u, err := url.Parse(companyURLs[company])
+		if err != nil || u.Host == "" {
+			log.Printf("no URL for rate limited company %s", company)
+			continue
+		}
+		limits[u.Host] = http.RateLimit{Interval: interval, Burst: config.RateLimitBurst}
+	}
+	return limits
+}
+
+func loadLocation() *time.Location {
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		log.Printf("error loading timezone %s, using local time: %v", config.Timezone, err)
+		return time.Local
+	}
+	return location
+}
+
+// loadStates restores the state of every scraper saved by a previous run
+func loadStates() map[string]*store.ScraperState {
+	states := make(map[string]*store.ScraperState, len(scrapersTypes))
+	for _, scraperType := range scrapersTypes {
+		state, err := store.LoadScraperState(statePath(scraperType))
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Printf("[%s] error loading state, starting empty: %v", scraperType, err)
+			}
+			state = store.NewScraperState()
+		}
+		states[scraperType] = state
+	}
+	return states
+}
+
+// loadHistory reads the listings of the digest periods saved before a restart
+func loadHistory() *digest.History {
+	retention := digest.WeeklyPeriod + digest.DailyPeriod
+	history, err := digest.LoadHistory(config.HistoryFile, retention, time.Now())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("error loading digest history, starting empty: %v", err)
+		}
+		return digest.NewHistory(retention)
+	}
+	return history
+}
+
+// saveHistory persists the listings of the digest periods
+func saveHistory(name string, history *digest.History) {
+	if err := history.Save(config.HistoryFile); err != nil {
+		log.Printf("[%s] error saving digest history: %v", name, err)
+	}
+}
+
+// saveState persists the state of a scraper, forgetting listings removed long ago
+func saveState(name string, state *store.ScraperState) {
+	state.Prune(time.Now().Add(-config.StatsRetention))
+	if err := state.Save(statePath(name)); err != nil {
+		log.Printf("[%s] error saving state: %v", name, err)
+	}
+}
+
+// archiveListings keeps every scraped listing for later queries
+func archiveListings(name string, listingArchive *archive.Archive, listings []common.Listing) {
+	if _, err := listingArchive.Add(listings, time.Now()); err != nil {
+		log.Printf("[%s] error archiving listings: %v", name, err)
+	}
+}
+
+// markDuplicate records the other listings of a duplicate flat in the archive
+func markDuplicate(name string, listingArchive *archive.Archive, listing common.Listing, others []dedup.Source) {
+	keys := make([]string, len(others))
+	for i, source := range others {
+		keys[i] = source.Key()
+	}
+	if err := listingArchive.MarkDuplicate(listing, keys); err != nil {
+		log.Printf("[%s] error archiving duplicate %s: %v", name, listing.ID, err)
 	}
+}
 
-	httpClient := http.NewClient(5 * time.Second)
-	scraperFactory := factory.NewScraperFactory(httpClient)
+// listingIDs returns the IDs of a scrape
+func listingIDs(listings []common.Listing) map[string]bool {
+	ids := make(map[string]bool, len(listings))
+	for _, listing := range listings {
+		ids[listing.ID] = true
+	}
+	return ids
+}
 
-	startAllScrapers(ctx, scraperFactory, telegramClient)
+func statePath(name string) string {
+	return filepath.Join(config.StateDir, name+".json")
+}
 
-	select {}
+// companyStats computes the listing lifetimes of every scraper
+func companyStats(states map[string]*store.ScraperState, location *time.Location) []stats.Company {
+	var companies []stats.Company
+	for _, scraperType := range scrapersTypes {
+		companies = append(companies, stats.Compute(scraperType, states[scraperType].Snapshots(), location))
+	}
+	return companies
 }
 
-func startAllScrapers(ctx context.Context, factory *factory.DefaultScraperFactory, client *telegram.Client) {
+func startAllScrapers(ctx context.Context, factory *factory.DefaultScraperFactory, states map[string]*store.ScraperState, dispatcher *notify.Dispatcher, history *digest.History, duplicates *dedup.Detector, listingArchive *archive.Archive, monitor *health.Monitor, adminNotifier *admin.Notifier, scraperMetrics *metrics.Metrics) {
 	var wg sync.WaitGroup
 	for _, scraperType := range scrapersTypes {
 		wg.Add(1)
-		scraper := factory.CreateScraper(scraperType, store.NewScraperState())
+		scraper := factory.CreateScraper(scraperType, states[scraperType])
 		if scraper == nil {
 			log.Printf("unknown scraper type: %s", scraperType)
 			wg.Done()
@@ -73,29 +374,41 @@ func startAllScrapers(ctx context.Context, factory *factory.DefaultScraperFactor
 		// start scraper in its own go routine
 		go func(s common.Scraper) {
 			defer wg.Done()
-			startScraper(ctx, s, client)
+			startScraper(ctx, s, dispatcher, history, duplicates, listingArchive, monitor, adminNotifier, scraperMetrics)
 		}(scraper)
 	}
 }
 
-func startScraper(ctx context.Context, scraper common.Scraper, client *telegram.Client) {
+func startScraper(ctx context.Context, scraper common.Scraper, dispatcher *notify.Dispatcher, history *digest.History, duplicates *dedup.Detector, listingArchive *archive.Archive, monitor *health.Monitor, adminNotifier *admin.Notifier, scraperMetrics *metrics.Metrics) {
 	name := scraper.GetName()
 	state := scraper.GetState()
 	allUsers := users.LoadFromStaticConfig()
 	log.Printf("[%s] starting scraper", name)
+	if len(state.Snapshots()) > 0 {
+		monitor.HadListings(name)
+	}
 
 	// Initial scrape without notifications - mark existing listings as seen
+	start := time.Now()
 	initialListings, err := scraper.Scrape(ctx)
+	scraperMetrics.ObserveScrape(name, time.Since(start), initialListings, err)
+	monitor.Report(ctx, name, initialListings, err)
 	if err != nil {
 		log.Printf("[%s] error during initial scrape: %v", name, err)
 	} else {
+		listed := listingIDs(initialListings)
 		for _, listing := range initialListings {
 			log.Printf("[%s] Storing initial listing: %s", name, listing.ID)
 			state.MarkAsSeen(listing.ID)
+			duplicates.Check(listing, listed)
 		}
+		state.Observe(prices(initialListings), time.Now())
+		saveState(name, state)
+		archiveListings(name, listingArchive, initialListings)
 	}
 
 	log.Printf("[%s] scraper store initialized", name)
+	failures := reportFailures(ctx, adminNotifier, name, 0, err)
 
 	// start monitoring for new listings
 	for {
@@ -106,30 +419,146 @@ func startScraper(ctx context.Context, scraper common.Scraper, client *telegram.
 		default:
 			time.Sleep(bot.GenerateRandomJitterTime())
 
+			start := time.Now()
 			listings, err := scraper.Scrape(ctx)
+			scraperMetrics.ObserveScrape(name, time.Since(start), listings, err)
+			monitor.Report(ctx, name, listings, err)
+			failures = reportFailures(ctx, adminNotifier, name, failures, err)
+			if errors.Is(err, common.ErrUnchanged) {
+				state.Touch(time.Now())
+				saveState(name, state)
+				continue
+			}
 			if err != nil {
 				log.Printf("[%s] Error during scrape: %v", name, err)
 				continue
 			}
+			archiveListings(name, listingArchive, listings)
 
 			// Check for new listings and send notifications
+			var newListings []common.Listing
+			listed := listingIDs(listings)
+			recorded := false
 			for _, listing := range listings {
 				if !state.Exists(listing.ID) {
 					log.Printf("[%s] New listing: %s", name, listing.ID)
+					scraperMetrics.NewListing(name)
 					state.MarkAsSeen(listing.ID)
-
-					// todo figure out how filtering works with telegram
-					if common.SendNotification(listing, allUsers) {
-						log.Printf("[%s] FILTER MATCH Sending Listing: %s", name, listing.ID)
-
-						// Convert to telegram format and send
-						telegramInfo := listing.ToTelegramInfo()
-						if err := client.SendListing(ctx, telegramInfo); err != nil {
-							log.Printf("[%s] Failed to send notification: %v", listing.ID, err)
-						}
+					history.Record(listing, time.Now())
+					recorded = true
+					if others, dup := duplicates.Check(listing, listed); dup {
+						log.Printf("[%s] Duplicate listing %s, already seen as %s %s", name, listing.ID, others[0].Company, others[0].ID)
+						markDuplicate(name, listingArchive, listing, others)
+						continue
 					}
+					newListings = append(newListings, listing)
 				}
 			}
+			if recorded {
+				saveHistory(name, history)
+			}
+			notifyUsers(ctx, name, newListings, allUsers, dispatcher, scraperMetrics)
+
+			events := state.Observe(prices(listings), time.Now())
+			notifyChanges(ctx, name, events, listings, allUsers, dispatcher)
+			saveState(name, state)
+		}
+	}
+}
+
+// reportFailures counts the failed scrapes in a row and tells the admins when there are
+// config.AdminScrapeFailures of them and when the scraper works again
+func reportFailures(ctx context.Context, adminNotifier *admin.Notifier, name string, failures int, err error) int {
+	if err != nil && !errors.Is(err, common.ErrUnchanged) {
+		if ctx.Err() != nil {
+			return failures // shutting down
+		}
+		failures++
+		if failures == config.AdminScrapeFailures {
+			adminNotifier.Notify(ctx, "scrape failing: "+name, fmt.Sprintf("⚠️ <b>%s</b> failed %d scrapes in a row\n%s",
+				name, failures, html.EscapeString(err.Error())))
+		}
+		return failures
+	}
+	if failures >= config.AdminScrapeFailures {
+		adminNotifier.Notify(ctx, "scrape recovered: "+name, fmt.Sprintf("✅ <b>%s</b> is scraping again after %d failures", name, failures))
+	}
+	return 0
+}
+
+// notifyChanges tells users about known listings that now match after a rent change, also
+// when they come back online with a new rent
+func notifyChanges(ctx context.Context, name string, events []store.Event, listings []common.Listing, allUsers *users.FilterConfig, dispatcher *notify.Dispatcher) {
+	byID := make(map[string]common.Listing, len(listings))
+	for _, listing := range listings {
+		byID[listing.ID] = listing
+	}
+
+	for _, event := range events {
+		log.Printf("[%s] %s", name, event)
+		listing, ok := byID[event.ID]
+		if !ok {
+			continue // removed
+		}
+		previous := listing
+		previous.Price = event.OldPrice
+
+		for i := range allUsers.Users {
+			user := &allUsers.Users[i]
+			// users the listing already matched before were told about it
+			if !listing.MatchUserConfig(user) || previous.MatchUserConfig(user) {
+				continue
+			}
+
+			var err error
+			switch {
+			case event.Type == store.PriceChanged && config.NotifyPriceChanges:
+				err = dispatcher.NotifyUpdate(ctx, user, listing, "Price change", common.PriceChangeNote(previous, listing))
+			case event.Type == store.Relisted && config.NotifyRelisted:
+				err = dispatcher.NotifyUpdate(ctx, user, listing, "Relisted", common.PriceChangeNote(previous, listing))
+			default:
+				continue
+			}
+			if err != nil {
+				log.Printf("[%s] Failed to send update notification: %v", listing.ID, err)
+			}
+		}
+	}
+}
+
+// prices maps listing IDs to their scraped rent for change tracking
+func prices(listings []common.Listing) map[string]string {
+	result := make(map[string]string, len(listings))
+	for _, listing := range listings {
+		result[listing.ID] = listing.Price
+	}
+	return result
+}
+
+// notifyUsers sends every user their matches, best score first, followed by near matches
+func notifyUsers(ctx context.Context, name string, listings []common.Listing, allUsers *users.FilterConfig, dispatcher *notify.Dispatcher, scraperMetrics *metrics.Metrics) {
+	for i := range allUsers.Users {
+		user := &allUsers.Users[i]
+
+		for _, listing := range common.RankedMatches(listings, user) {
+			log.Printf("[%s] FILTER MATCH Sending Listing: %s", name, listing.ID)
+			scraperMetrics.Match(name, user.UserID)
+
+			if err := dispatcher.Notify(ctx, user, listing); err != nil {
+				log.Printf("[%s] Failed to send notification: %v", listing.ID, err)
+			}
+		}
+
+		for _, listing := range listings {
+			misses, ok := listing.NearMatch(user)
+			if !ok {
+				continue
+			}
+			log.Printf("[%s] NEAR MATCH Sending Listing: %s", name, listing.ID)
+
+			if err := dispatcher.NotifyNearMatch(ctx, user, listing, misses); err != nil {
+				log.Printf("[%s] Failed to send near match notification: %v", listing.ID, err)
+			}
 		}
 	}
 }
@@ -0,0 +1,108 @@
+// Package admin sends operational alerts, like failing scrapers or undeliverable
+// notifications, to a chat of the operators separate from the listing notifications.
+package admin
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Sender delivers an HTML message to a chat, implemented by telegram.Client
+type Sender interface {
+	SendMessageTo(ctx context.Context, chatID, htmlMessage string) error
+}
+
+type sent struct {
+	at         time.Time
+	suppressed int // identical alerts dropped since
+}
+
+// Notifier sends alerts to the admin chat. Alerts with the same key are sent at most once
+// per dedup window, and at most limit alerts are sent per throttle window so a flapping
+// site does not flood the chat. Every alert is logged.
+type Notifier struct {
+	sender      Sender
+	chatID      string // empty only logs
+	dedupWindow time.Duration
+	limit       int
+	window      time.Duration
+	now         func() time.Time
+
+	mu        sync.Mutex
+	last      map[string]*sent
+	recent    []time.Time // sent within window
+	throttled int         // alerts dropped since the last one sent
+}
+
+// NewNotifier creates a notifier sending to chatID, it only logs if chatID is empty
+func NewNotifier(sender Sender, chatID string, dedupWindow time.Duration, limit int, window time.Duration) *Notifier {
+	return &Notifier{
+		sender:      sender,
+		chatID:      chatID,
+		dedupWindow: dedupWindow,
+		limit:       limit,
+		window:      window,
+		now:         time.Now,
+		last:        make(map[string]*sent),
+	}
+}
+
+// Notify sends an alert unless one with the same key was sent recently or too many
+// alerts were sent lately
+func (n *Notifier) Notify(ctx context.Context, key, htmlMessage string) {
+	log.Printf("[admin] %s", htmlMessage)
+
+	message, ok := n.admit(key, htmlMessage)
+	if !ok || n.chatID == "" {
+		return
+	}
+	if err := n.sender.SendMessageTo(ctx, n.chatID, message); err != nil {
+		log.Printf("[admin] error sending alert: %v", err)
+	}
+}
+
+// Alert sends an alert like Notify, so Notifier can be used as health.Alerter
+func (n *Notifier) Alert(ctx context.Context, key, htmlMessage string) error {
+	n.Notify(ctx, key, htmlMessage)
+	return nil
+}
+
+// admit applies de-duplication and throttling, returning the message to send with a
+// note about dropped alerts
+func (n *Notifier) admit(key, htmlMessage string) (string, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := n.now()
+
+	previous := n.last[key]
+	if previous != nil && now.Sub(previous.at) < n.dedupWindow {
+		previous.suppressed++
+		return "", false
+	}
+
+	recent := n.recent[:0]
+	for _, at := range n.recent {
+		if now.Sub(at) < n.window {
+			recent = append(recent, at)
+		}
+	}
+	n.recent = recent
+	if n.limit > 0 && len(n.recent) >= n.limit {
+		n.throttled++
+		return "", false
+	}
+	n.recent = append(n.recent, now)
+
+	if previous != nil && previous.suppressed > 0 {
+		htmlMessage += fmt.Sprintf("\n<i>repeated %d times since %s</i>", previous.suppressed, previous.at.Format("15:04"))
+	}
+	if n.throttled > 0 {
+		htmlMessage += fmt.Sprintf("\n<i>%d other alerts were throttled</i>", n.throttled)
+		n.throttled = 0
+	}
+	n.last[key] = &sent{at: now}
+	return htmlMessage, true
+}
@@ -0,0 +1,149 @@
+package admin
+
+import (
+	"apartmenthunter/internal/telegram"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sentMessage struct {
+	chatID  string
+	message string
+}
+
+type stubSender struct {
+	sent []sentMessage
+}
+
+func (s *stubSender) SendMessageTo(ctx context.Context, chatID, htmlMessage string) error {
+	s.sent = append(s.sent, sentMessage{chatID, htmlMessage})
+	return nil
+}
+
+// TestNotifier_Notify tests de-duplication and throttling of alerts
+func TestNotifier_Notify(t *testing.T) {
+	type alert struct {
+		after time.Duration // since the previous alert
+		key   string
+	}
+	tests := []struct {
+		name   string
+		alerts []alert
+		want   []string
+	}{
+		{
+			name:   "different keys",
+			alerts: []alert{{0, "a"}, {time.Minute, "b"}},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "same key within window",
+			alerts: []alert{{0, "a"}, {time.Minute, "a"}, {time.Minute, "a"}},
+			want:   []string{"a"},
+		},
+		{
+			name:   "same key after window",
+			alerts: []alert{{0, "a"}, {time.Minute, "a"}, {time.Hour, "a"}},
+			want:   []string{"a", "a\n<i>repeated 1 times since 10:00</i>"},
+		},
+		{
+			name:   "throttled",
+			alerts: []alert{{0, "a"}, {time.Minute, "b"}, {time.Minute, "c"}, {time.Minute, "d"}},
+			want:   []string{"a", "b", "c"},
+		},
+		{
+			name:   "throttled alerts are counted",
+			alerts: []alert{{0, "a"}, {time.Minute, "b"}, {time.Minute, "c"}, {time.Minute, "d"}, {2 * time.Hour, "e"}},
+			want:   []string{"a", "b", "c", "e\n<i>1 other alerts were throttled</i>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &stubSender{}
+			n := NewNotifier(sender, "admins", 30*time.Minute, 3, time.Hour)
+			now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+			n.now = func() time.Time { return now }
+
+			for _, a := range tt.alerts {
+				now = now.Add(a.after)
+				n.Notify(context.Background(), a.key, a.key)
+			}
+
+			if len(sender.sent) != len(tt.want) {
+				t.Fatalf("sent %v, want %q", sender.sent, tt.want)
+			}
+			for i, want := range tt.want {
+				if sender.sent[i].message != want || sender.sent[i].chatID != "admins" {
+					t.Errorf("message %d = %+v, want %q to admins", i, sender.sent[i], want)
+				}
+			}
+		})
+	}
+}
+
+// TestNotifier_Alert tests that alerts are de-duplicated by key, not by their text
+func TestNotifier_Alert(t *testing.T) {
+	sender := &stubSender{}
+	n := NewNotifier(sender, "admins", time.Hour, 10, time.Hour)
+	_ = n.Alert(context.Background(), "WBM no listings", "no listings in the last 3 scrapes")
+	_ = n.Alert(context.Background(), "WBM no listings", "no listings in the last 4 scrapes")
+	_ = n.Alert(context.Background(), "Howoge no listings", "no listings in the last 3 scrapes")
+	if len(sender.sent) != 2 || sender.sent[1].message != "no listings in the last 3 scrapes" {
+		t.Errorf("sent %v, want one alert per key", sender.sent)
+	}
+}
+
+// TestNotifier_NoChat tests that alerts are only logged without an admin chat
+func TestNotifier_NoChat(t *testing.T) {
+	sender := &stubSender{}
+	n := NewNotifier(sender, "", time.Hour, 10, time.Hour)
+	n.Notify(context.Background(), "startup", "running")
+	if len(sender.sent) != 0 {
+		t.Errorf("sent %v without admin chat", sender.sent)
+	}
+}
+
+// TestReportErrors tests that failed deliveries are reported without the bot token
+func TestReportErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "secret-token") && r.FormValue("chat_id") == "user" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	client, err := telegram.NewClient(server.URL, "secret-token", "user")
+	if err != nil {
+		t.Fatalf("NewClient() unexpected error: %v", err)
+	}
+	sender := &stubSender{}
+	reporting := ReportErrors(client, NewNotifier(sender, "admins", time.Hour, 10, time.Hour))
+
+	if err := reporting.SendMessageTo(context.Background(), "admins", "ok"); err != nil {
+		t.Fatalf("SendMessageTo() unexpected error: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("reported %v for a delivered message", sender.sent)
+	}
+
+	if err := reporting.SendMessageWithOptions(context.Background(), "listing", telegram.MessageOptions{ChatID: "user"}); err == nil {
+		t.Fatal("SendMessageWithOptions() expected error")
+	}
+	if len(sender.sent) != 1 || !strings.Contains(sender.sent[0].message, "403 Forbidden") {
+		t.Fatalf("reported %v, want the failed delivery", sender.sent)
+	}
+
+	// transport errors contain the URL
+	server.Close()
+	sender.sent = nil
+	reporting = ReportErrors(client, NewNotifier(sender, "admins", time.Hour, 10, time.Hour))
+	_ = reporting.SendMessageWithOptions(context.Background(), "listing", telegram.MessageOptions{ChatID: "user"})
+	if len(sender.sent) != 1 || strings.Contains(sender.sent[0].message, "secret-token") {
+		t.Errorf("reported %v, want one report without the bot token", sender.sent)
+	}
+}
@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"apartmenthunter/internal/telegram"
+	"context"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// ReportingClient is a telegram client telling the admins about messages it failed to
+// deliver to users
+type ReportingClient struct {
+	*telegram.Client
+	notifier *Notifier
+}
+
+// ReportErrors wraps client, the notifier itself must send through the plain client
+func ReportErrors(client *telegram.Client, notifier *Notifier) *ReportingClient {
+	return &ReportingClient{Client: client, notifier: notifier}
+}
+
+func (c *ReportingClient) SendMessageTo(ctx context.Context, chatID, htmlMessage string) error {
+	return c.SendMessageWithOptions(ctx, htmlMessage, telegram.MessageOptions{ChatID: chatID})
+}
+
+func (c *ReportingClient) SendMessageWithOptions(ctx context.Context, htmlMessage string, opts telegram.MessageOptions) error {
+	err := c.Client.SendMessageWithOptions(ctx, htmlMessage, opts)
+	if err != nil && ctx.Err() == nil {
+		// request errors contain the API URL with the bot token
+		text := err.Error()
+		if c.BotToken != "" {
+			text = strings.ReplaceAll(text, c.BotToken, "<token>")
+		}
+		c.notifier.Notify(ctx, "telegram", fmt.Sprintf("⚠️ <b>Telegram delivery failed</b>\n%s", html.EscapeString(text)))
+	}
+	return err
+}
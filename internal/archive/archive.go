@@ -0,0 +1,222 @@
+// Package archive keeps every scraped listing in an embedded bbolt database so past
+// offers can be queried later, e.g. how many 2-room flats Gewobag posted last month.
+package archive
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/wbs"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var listingsBucket = []byte("listings")
+
+// lockTimeout is how long to wait for the database while another process uses it
+const lockTimeout = 10 * time.Second
+
+// Record is an archived listing with the fields as scraped
+type Record struct {
+	Company     string          `json:"company"`
+	ID          string          `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	Price       string          `json:"price"`
+	RentType    common.RentType `json:"rent_type"`
+	Size        string          `json:"size"`
+	Rooms       string          `json:"rooms"`
+	Floor       string          `json:"floor,omitempty"`
+	Balcony     bool            `json:"balcony"`
+	Address     string          `json:"address"`
+	ZipCode     string          `json:"zip_code"`
+	District    string          `json:"district"`
+	Lat         float64         `json:"lat,omitempty"`
+	Lon         float64         `json:"lon,omitempty"`
+	Wbs         wbs.Requirement `json:"wbs"`
+	URL         string          `json:"url"`
+	FirstSeen   time.Time       `json:"first_seen"`
+	LastSeen    time.Time       `json:"last_seen"`
+	DuplicateOf []string        `json:"duplicate_of,omitempty"` // "Company/ID" of earlier listings of the flat
+}
+
+// Archive stores listings in a bbolt file. The database is only opened while reading or
+// writing, so the query command can be used while the bot is running.
+type Archive struct {
+	path string
+	mu   sync.Mutex // serialises writers of this process
+}
+
+// New returns an archive stored at path, the file is created on the first write
+func New(path string) *Archive {
+	return &Archive{path: path}
+}
+
+// Add archives the listings seen at now. Known listings keep their first sighting and
+// get their fields updated. Returns the number of listings archived for the first time.
+func (a *Archive) Add(listings []common.Listing, now time.Time) (int, error) {
+	added := 0
+	err := a.update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(listingsBucket)
+		if err != nil {
+			return err
+		}
+		for _, listing := range listings {
+			record := recordOf(listing)
+			record.FirstSeen, record.LastSeen = now, now
+
+			key := []byte(record.Company + "/" + record.ID)
+			if data := bucket.Get(key); data != nil {
+				var existing Record
+				if err := json.Unmarshal(data, &existing); err == nil {
+					record.FirstSeen = existing.FirstSeen
+					record.DuplicateOf = existing.DuplicateOf
+				}
+			} else {
+				added++
+			}
+			if err := putJSON(bucket, key, record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return added, err
+}
+
+// MarkDuplicate records that the archived listing is the same flat as the listings with
+// the keys of, e.g. "Howoge/7412"
+func (a *Archive) MarkDuplicate(l common.Listing, of []string) error {
+	return a.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(listingsBucket)
+		if bucket == nil {
+			return fmt.Errorf("listing %s/%s is not archived", l.Company, l.ID)
+		}
+		key := []byte(l.Company + "/" + l.ID)
+		data := bucket.Get(key)
+		if data == nil {
+			return fmt.Errorf("listing %s is not archived", key)
+		}
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("record %s: %w", key, err)
+		}
+		for _, source := range of {
+			if !slices.Contains(record.DuplicateOf, source) {
+				record.DuplicateOf = append(record.DuplicateOf, source)
+			}
+		}
+		return putJSON(bucket, key, record)
+	})
+}
+
+// Query returns the archived listings matching q, ordered by first sighting
+func (a *Archive) Query(q Query) ([]Record, error) {
+	var records []Record
+	err := a.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(listingsBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, data []byte) error {
+			var record Record
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("record %s: %w", key, err)
+			}
+			if q.Matches(record) {
+				records = append(records, record)
+			}
+			return nil
+		})
+	})
+	sort.Slice(records, func(i, j int) bool { return records[i].FirstSeen.Before(records[j].FirstSeen) })
+	return records, err
+}
+
+// update runs fn in a write transaction, creating the database if needed
+func (a *Archive) update(fn func(tx *bolt.Tx) error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+		return err
+	}
+	db, err := bolt.Open(a.path, 0o644, &bolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+// view runs fn in a read-only transaction, failing if nothing has been archived yet
+func (a *Archive) view(fn func(tx *bolt.Tx) error) error {
+	if _, err := os.Stat(a.path); err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	db, err := bolt.Open(a.path, 0o644, &bolt.Options{Timeout: lockTimeout, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func putJSON(bucket *bolt.Bucket, key []byte, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
+
+func recordOf(l common.Listing) Record {
+	return Record{
+		Company:     l.Company,
+		ID:          l.ID,
+		Title:       l.Title,
+		Description: l.Description,
+		Price:       l.Price,
+		RentType:    l.RentType,
+		Size:        l.Size,
+		Rooms:       l.Rooms,
+		Floor:       l.Floor,
+		Balcony:     l.Balcony,
+		Address:     l.Address,
+		ZipCode:     l.ZipCode,
+		District:    l.District,
+		Lat:         l.Lat,
+		Lon:         l.Lon,
+		Wbs:         l.Wbs,
+		URL:         l.URL,
+	}
+}
+
+// Listing converts the record back into a listing to reuse its parsing and matching
+func (r Record) Listing() common.Listing {
+	return common.Listing{
+		ID:          r.ID,
+		Company:     r.Company,
+		Title:       r.Title,
+		Description: r.Description,
+		Price:       r.Price,
+		RentType:    r.RentType,
+		Size:        r.Size,
+		Address:     r.Address,
+		URL:         r.URL,
+		ZipCode:     r.ZipCode,
+		District:    r.District,
+		Lat:         r.Lat,
+		Lon:         r.Lon,
+		Wbs:         r.Wbs,
+		Rooms:       r.Rooms,
+		Floor:       r.Floor,
+		Balcony:     r.Balcony,
+	}
+}
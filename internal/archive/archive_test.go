@@ -0,0 +1,162 @@
+package archive
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testListings() []common.Listing {
+	return []common.Listing{
+		{ID: "1", Company: "Gewobag", Price: "850", RentType: common.RentWarm, Size: "55", Rooms: "2", ZipCode: "12047", District: "Neukölln", Address: "Weserstraße 5, 12047 Berlin"},
+		{ID: "2", Company: "Gewobag", Price: "1.200,00", RentType: common.RentWarm, Size: "80", Rooms: "3", ZipCode: "12047", Address: "Weserstraße 7, 12047 Berlin"},
+		{ID: "3", Company: "WBM", Price: "700", Size: "45", Rooms: "2", ZipCode: "10247", Address: "Boxhagener Straße 1, 10247 Berlin"},
+	}
+}
+
+// TestArchive_Add tests archiving listings across scrapes
+func TestArchive_Add(t *testing.T) {
+	a := New(filepath.Join(t.TempDir(), "archive", "listings.db"))
+	first := time.Date(2026, 9, 10, 8, 0, 0, 0, time.UTC)
+
+	added, err := a.Add(testListings(), first)
+	if err != nil || added != 3 {
+		t.Fatalf("Add() = %d, %v, want 3 new listings", added, err)
+	}
+
+	updated := testListings()[:1]
+	updated[0].Price = "820"
+	later := first.Add(time.Hour)
+	if added, err := a.Add(updated, later); err != nil || added != 0 {
+		t.Fatalf("Add() of a known listing = %d, %v, want 0", added, err)
+	}
+
+	records, err := a.Query(Query{Company: "gewobag", ZipCode: "12047", MaxPrice: 900})
+	if err != nil {
+		t.Fatalf("Query() unexpected error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Query() returned %d records, want 1", len(records))
+	}
+	r := records[0]
+	if r.Price != "820" || !r.FirstSeen.Equal(first) || !r.LastSeen.Equal(later) || r.RentType != common.RentWarm {
+		t.Errorf("Query() = %+v, want updated price with the first sighting kept", r)
+	}
+}
+
+// TestArchive_MarkDuplicate tests that duplicate sources are kept across scrapes
+func TestArchive_MarkDuplicate(t *testing.T) {
+	a := New(filepath.Join(t.TempDir(), "listings.db"))
+	now := time.Date(2026, 9, 10, 8, 0, 0, 0, time.UTC)
+	listing := testListings()[2]
+
+	if err := a.MarkDuplicate(listing, []string{"Gewobag/1"}); err == nil {
+		t.Error("MarkDuplicate() of a listing not archived should fail")
+	}
+	if _, err := a.Add(testListings(), now); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if err := a.MarkDuplicate(listing, []string{"Gewobag/1"}); err != nil {
+		t.Fatalf("MarkDuplicate() unexpected error: %v", err)
+	}
+	if err := a.MarkDuplicate(listing, []string{"Gewobag/1", "Howoge/9"}); err != nil {
+		t.Fatalf("MarkDuplicate() unexpected error: %v", err)
+	}
+	if _, err := a.Add(testListings()[2:], now.Add(time.Hour)); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	records, err := a.Query(Query{Company: "WBM"})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("Query() = %v, %v, want the WBM listing", records, err)
+	}
+	if got := strings.Join(records[0].DuplicateOf, " "); got != "Gewobag/1 Howoge/9" {
+		t.Errorf("DuplicateOf = %q, want both sources once", got)
+	}
+}
+
+// TestArchive_QueryMissing tests querying before anything was archived
+func TestArchive_QueryMissing(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "missing.db")).Query(Query{}); err == nil {
+		t.Error("Query() of a missing archive should fail")
+	}
+}
+
+// TestQuery_Matches tests the archive filters
+func TestQuery_Matches(t *testing.T) {
+	seen := time.Date(2026, 9, 10, 8, 0, 0, 0, time.UTC)
+	record := recordOf(testListings()[0])
+	record.FirstSeen = seen
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected bool
+	}{
+		{"no filters", Query{}, true},
+		{"company", Query{Company: "WBM"}, false},
+		{"district by name", Query{District: "Neukölln"}, true},
+		{"bezirk", Query{District: "Friedrichshain-Kreuzberg"}, false},
+		{"rooms", Query{MinRooms: 2, MaxRooms: 2}, true},
+		{"too few rooms", Query{MinRooms: 3}, false},
+		{"price range", Query{MinPrice: 800, MaxPrice: 900}, true},
+		{"size", Query{MaxSqm: 50}, false},
+		{"date range", Query{From: seen.AddDate(0, 0, -9), To: seen.AddDate(0, 0, 20)}, true},
+		{"to is exclusive", Query{To: seen}, false},
+		{"before range", Query{From: seen.Add(time.Second)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.query.Matches(record); result != tt.expected {
+				t.Errorf("Matches() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+
+	unparseable := recordOf(common.Listing{Price: "auf Anfrage"})
+	if (Query{MaxPrice: 900}).Matches(unparseable) {
+		t.Error("Matches() should reject records without a price when filtering by price")
+	}
+}
+
+// TestWrite tests the output formats
+func TestWrite(t *testing.T) {
+	records := []Record{recordOf(testListings()[0])}
+	records[0].FirstSeen = time.Date(2026, 9, 10, 8, 0, 0, 0, time.UTC)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation() unexpected error: %v", err)
+	}
+	var table bytes.Buffer
+	if err := Write(&table, records, FormatTable, berlin); err != nil {
+		t.Fatalf("Write(table) unexpected error: %v", err)
+	}
+	if !strings.Contains(table.String(), "Weserstraße 5") || !strings.Contains(table.String(), "1 listings") ||
+		!strings.Contains(table.String(), "2026-09-10 10:00") {
+		t.Errorf("Write(table) = %s", table.String())
+	}
+
+	var csv bytes.Buffer
+	_ = Write(&csv, records, FormatCSV, berlin)
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "first_seen,last_seen,company") || !strings.Contains(lines[1], ",Gewobag,1,12047,") {
+		t.Errorf("Write(csv) = %s", csv.String())
+	}
+
+	var out bytes.Buffer
+	_ = Write(&out, records, FormatJSON, berlin)
+	var decoded []Record
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded) != 1 || decoded[0].RentType != common.RentWarm {
+		t.Errorf("Write(json) = %s, %v", out.String(), err)
+	}
+
+	if err := Write(&out, records, "xml", berlin); err == nil {
+		t.Error("Write() with an unknown format should fail")
+	}
+}
@@ -0,0 +1,65 @@
+package archive
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Formats supported by Write
+const (
+	FormatTable = "table"
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+)
+
+var columns = []string{"first_seen", "last_seen", "company", "id", "zip_code", "district", "rooms", "size", "price", "rent_type", "wbs", "address", "url"}
+
+// Write prints the records as an aligned table with the times in location, CSV with a
+// header row or a JSON array
+func Write(w io.Writer, records []Record, format string, location *time.Location) error {
+	switch format {
+	case FormatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "FIRST SEEN\tCOMPANY\tID\tZIP\tDISTRICT\tROOMS\tSIZE\tPRICE\tADDRESS")
+		for _, r := range records {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				r.FirstSeen.In(location).Format("2006-01-02 15:04"), r.Company, r.ID, r.ZipCode, r.District, r.Rooms, r.Size, r.Price, r.Address)
+		}
+		fmt.Fprintf(tw, "\n%d listings\n", len(records))
+		return tw.Flush()
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+		for _, r := range records {
+			wbsText := ""
+			if r.Wbs.Required {
+				wbsText = r.Wbs.String()
+			}
+			row := []string{
+				r.FirstSeen.Format(time.RFC3339), r.LastSeen.Format(time.RFC3339), r.Company, r.ID, r.ZipCode, r.District,
+				r.Rooms, r.Size, r.Price, r.RentType.String(), wbsText, r.Address, r.URL,
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		if records == nil {
+			records = []Record{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	default:
+		return fmt.Errorf("unknown format %s, use %s, %s or %s", strconv.Quote(format), FormatTable, FormatCSV, FormatJSON)
+	}
+}
@@ -0,0 +1,171 @@
+package archive
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var matchesBucket = []byte("matches")
+
+// FormatJSONL writes one JSON object per line
+const FormatJSONL = "jsonl"
+
+// DefaultUser stores the matches of the user without an ID, the static default user, as
+// bbolt buckets need a name
+const DefaultUser = "default"
+
+// UserKey is the bucket and export file name of a user's matches
+func UserKey(userID string) string {
+	if userID == "" {
+		return DefaultUser
+	}
+	return userID
+}
+
+// Match is a listing that matched a user's filters together with its notification status
+type Match struct {
+	Record
+	UserID    string    `json:"user_id"`
+	Status    string    `json:"status"` // e.g. "sent", "queued" or "failed"
+	MatchedAt time.Time `json:"matched_at"`
+	UpdatedAt time.Time `json:"updated_at"` // of the status
+}
+
+type matchEntry struct {
+	Status    string    `json:"status"`
+	MatchedAt time.Time `json:"matched_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RecordMatch stores that a listing matched a user with the latest notification status.
+// The listing is archived as well if it is not yet.
+func (a *Archive) RecordMatch(userID string, listing common.Listing, status string, now time.Time) error {
+	return a.update(func(tx *bolt.Tx) error {
+		listingKey := []byte(listing.Company + "/" + listing.ID)
+		listings, err := tx.CreateBucketIfNotExists(listingsBucket)
+		if err != nil {
+			return err
+		}
+		if listings.Get(listingKey) == nil {
+			record := recordOf(listing)
+			record.FirstSeen, record.LastSeen = now, now
+			if err := putJSON(listings, listingKey, record); err != nil {
+				return err
+			}
+		}
+
+		matches, err := tx.CreateBucketIfNotExists(matchesBucket)
+		if err != nil {
+			return err
+		}
+		users, err := matches.CreateBucketIfNotExists([]byte(UserKey(userID)))
+		if err != nil {
+			return err
+		}
+		entry := matchEntry{Status: status, MatchedAt: now, UpdatedAt: now}
+		if data := users.Get(listingKey); data != nil {
+			var existing matchEntry
+			if err := json.Unmarshal(data, &existing); err == nil {
+				entry.MatchedAt = existing.MatchedAt
+			}
+		}
+		return putJSON(users, listingKey, entry)
+	})
+}
+
+// Matches returns the listings that matched the user, ordered by when they matched
+func (a *Archive) Matches(userID string) ([]Match, error) {
+	var result []Match
+	err := a.view(func(tx *bolt.Tx) error {
+		matches := tx.Bucket(matchesBucket)
+		listings := tx.Bucket(listingsBucket)
+		if matches == nil || listings == nil {
+			return nil
+		}
+		users := matches.Bucket([]byte(UserKey(userID)))
+		if users == nil {
+			return nil
+		}
+		return users.ForEach(func(key, data []byte) error {
+			var entry matchEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return fmt.Errorf("match %s: %w", key, err)
+			}
+			match := Match{UserID: userID, Status: entry.Status, MatchedAt: entry.MatchedAt, UpdatedAt: entry.UpdatedAt}
+			if record := listings.Get(key); record != nil {
+				if err := json.Unmarshal(record, &match.Record); err != nil {
+					return fmt.Errorf("record %s: %w", key, err)
+				}
+			}
+			result = append(result, match)
+			return nil
+		})
+	})
+	sort.Slice(result, func(i, j int) bool { return result[i].MatchedAt.Before(result[j].MatchedAt) })
+	return result, err
+}
+
+var matchColumns = []string{
+	"user_id", "status", "matched_at", "updated_at", "first_seen", "last_seen", "company", "id", "title", "description",
+	"price", "rent_type", "size", "rooms", "floor", "balcony", "address", "zip_code", "district", "lat", "lon", "wbs", "url",
+}
+
+// WriteMatches exports matches as CSV with a header row or as JSON Lines
+func WriteMatches(w io.Writer, matches []Match, format string) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(matchColumns); err != nil {
+			return err
+		}
+		for _, m := range matches {
+			wbsText := ""
+			if m.Wbs.Required {
+				wbsText = m.Wbs.String()
+			}
+			row := []string{
+				m.UserID, m.Status, formatTime(m.MatchedAt), formatTime(m.UpdatedAt), formatTime(m.FirstSeen), formatTime(m.LastSeen),
+				m.Company, m.ID, m.Title, m.Description, m.Price, m.RentType.String(), m.Size, m.Rooms, m.Floor,
+				strconv.FormatBool(m.Balcony), m.Address, m.ZipCode, m.District, formatCoordinate(m.Lat), formatCoordinate(m.Lon),
+				wbsText, m.URL,
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSONL:
+		encoder := json.NewEncoder(w)
+		for _, m := range matches {
+			if err := encoder.Encode(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %s, use %s or %s", strconv.Quote(format), FormatCSV, FormatJSONL)
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatCoordinate(c float64) string {
+	if c == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(c, 'f', 6, 64)
+}
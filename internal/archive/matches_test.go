@@ -0,0 +1,100 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestArchive_RecordMatch tests storing matches and their notification status
+func TestArchive_RecordMatch(t *testing.T) {
+	a := New(filepath.Join(t.TempDir(), "listings.db"))
+	first := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	listings := testListings()
+
+	if _, err := a.Add(listings[:1], first.Add(-time.Hour)); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	_ = a.RecordMatch("alice", listings[0], "queued", first)
+	_ = a.RecordMatch("alice", listings[2], "sent", first.Add(time.Minute))
+	_ = a.RecordMatch("alice", listings[0], "sent", first.Add(5*time.Hour))
+	_ = a.RecordMatch("bob", listings[1], "failed", first)
+
+	matches, err := a.Matches("alice")
+	if err != nil {
+		t.Fatalf("Matches() unexpected error: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Matches() returned %d matches, want 2", len(matches))
+	}
+	m := matches[0]
+	if m.ID != "1" || m.Status != "sent" || !m.MatchedAt.Equal(first) || !m.UpdatedAt.Equal(first.Add(5*time.Hour)) || !m.FirstSeen.Equal(first.Add(-time.Hour)) {
+		t.Errorf("Matches()[0] = %+v, want the latest status with the first match and sighting kept", m)
+	}
+	if matches[1].ID != "3" || matches[1].Address == "" {
+		t.Errorf("Matches()[1] = %+v, want the listing archived along with the match", matches[1])
+	}
+
+	if matches, err := a.Matches("carol"); err != nil || len(matches) != 0 {
+		t.Errorf("Matches() of a user without matches = %v, %v", matches, err)
+	}
+}
+
+// TestArchive_RecordMatchDefaultUser tests matches of the static default user without an ID
+func TestArchive_RecordMatchDefaultUser(t *testing.T) {
+	a := New(filepath.Join(t.TempDir(), "listings.db"))
+	now := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+
+	if err := a.RecordMatch("", testListings()[0], "sent", now); err != nil {
+		t.Fatalf("RecordMatch() with empty user ID unexpected error: %v", err)
+	}
+	matches, err := a.Matches("")
+	if err != nil || len(matches) != 1 || matches[0].UserID != "" || matches[0].ID != "1" {
+		t.Errorf("Matches(\"\") = %+v, %v, want the recorded match", matches, err)
+	}
+	if UserKey("") != DefaultUser || UserKey("alice") != "alice" {
+		t.Errorf("UserKey() = %q, %q", UserKey(""), UserKey("alice"))
+	}
+}
+
+// TestWriteMatches tests the CSV and JSON Lines exports
+func TestWriteMatches(t *testing.T) {
+	matched := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	matches := []Match{
+		{Record: recordOf(testListings()[0]), UserID: "alice", Status: "sent", MatchedAt: matched, UpdatedAt: matched},
+		{Record: recordOf(testListings()[2]), UserID: "alice", Status: "queued", MatchedAt: matched, UpdatedAt: matched},
+	}
+
+	var out bytes.Buffer
+	if err := WriteMatches(&out, matches, FormatCSV); err != nil {
+		t.Fatalf("WriteMatches(csv) unexpected error: %v", err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("WriteMatches(csv) = %v, %v, want a header and 2 rows", rows, err)
+	}
+	if len(rows[1]) != len(matchColumns) || rows[1][0] != "alice" || rows[1][1] != "sent" || rows[1][2] != "2026-10-19T08:00:00Z" {
+		t.Errorf("WriteMatches(csv) row = %v", rows[1])
+	}
+
+	out.Reset()
+	if err := WriteMatches(&out, matches, FormatJSONL); err != nil {
+		t.Fatalf("WriteMatches(jsonl) unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("WriteMatches(jsonl) wrote %d lines, want 2", len(lines))
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &decoded); err != nil || decoded["status"] != "queued" || decoded["company"] != "WBM" {
+		t.Errorf("WriteMatches(jsonl) line = %s, %v", lines[1], err)
+	}
+
+	if err := WriteMatches(&out, matches, FormatTable); err == nil {
+		t.Error("WriteMatches() with an unsupported format should fail")
+	}
+}
@@ -0,0 +1,60 @@
+package archive
+
+import (
+	"strings"
+	"time"
+)
+
+// Query filters archived listings, zero values do not restrict
+type Query struct {
+	Company  string
+	ZipCode  string
+	District string // Bezirk or Ortsteil
+	MinPrice float64
+	MaxPrice float64 // warm rent
+	MinSqm   float64
+	MaxSqm   float64
+	MinRooms float64
+	MaxRooms float64
+	From     time.Time // first seen at or after
+	To       time.Time // first seen before
+}
+
+// Matches reports whether the record passes every filter of the query. Records whose
+// value cannot be parsed do not pass a filter on that value.
+func (q Query) Matches(r Record) bool {
+	listing := r.Listing()
+	switch {
+	case q.Company != "" && !strings.EqualFold(q.Company, r.Company):
+		return false
+	case q.ZipCode != "" && q.ZipCode != r.ZipCode:
+		return false
+	case q.District != "" && !listing.InDistrict(q.District):
+		return false
+	case !q.From.IsZero() && r.FirstSeen.Before(q.From):
+		return false
+	case !q.To.IsZero() && !r.FirstSeen.Before(q.To):
+		return false
+	}
+
+	price, err := listing.PriceValue()
+	if !inRange(price, err, q.MinPrice, q.MaxPrice) {
+		return false
+	}
+	size, err := listing.SizeValue()
+	if !inRange(size, err, q.MinSqm, q.MaxSqm) {
+		return false
+	}
+	rooms, err := listing.RoomsValue()
+	return inRange(rooms, err, q.MinRooms, q.MaxRooms)
+}
+
+func inRange(value float64, err error, min, max float64) bool {
+	if min == 0 && max == 0 {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	return (min == 0 || value >= min) && (max == 0 || value <= max)
+}
@@ -1,33 +1,61 @@
 package bot
 
 import (
+	"golang.org/x/net/publicsuffix"
 	"math/rand"
+	"net/url"
 )
 
-var userAgents = []string{
-	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/132.0.0.0 Safari/537.36",
-	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/132.0.0.0 Safari/537.36",
-	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/132.0.0.0 Safari/537.36 Edg/132.0.0.0",
-	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:110.0) Gecko/20100101 Firefox/110.0",
-	"Mozilla/5.0 (iPhone; CPU iPhone OS 17_7 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
-	"Mozilla/5.0 (Linux; Android 13; Pixel 6) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/132.0.0.0 Mobile Safari/537.36",
-}
+// acceptEncoding lists the encodings the HTTP client can decode
+const acceptEncoding = "gzip, deflate, br"
 
-type HeaderGenerator struct{}
+// HeaderGenerator produces the headers of one browser, chosen when it is created and kept
+// for all requests so that a session looks like a single visitor
+type HeaderGenerator struct {
+	profile Profile
+}
 
+// NewHeaderGenerator picks a random browser profile
 func NewHeaderGenerator() *HeaderGenerator {
-	return &HeaderGenerator{}
+	return NewHeaderGeneratorFor(profiles[rand.Intn(len(profiles))])
 }
 
-func (h *HeaderGenerator) getRandomUserAgent() string {
-	return userAgents[rand.Intn(len(userAgents))]
+// NewHeaderGeneratorFor uses the given browser profile
+func NewHeaderGeneratorFor(profile Profile) *HeaderGenerator {
+	return &HeaderGenerator{profile: profile}
 }
 
-// GenerateGeneralRequestHeaders generate headers to help evade bot checks
-// only set origin if cross-origin post request, or ajax request
-func (h *HeaderGenerator) GenerateGeneralRequestHeaders(origin, referer string, formEncodedPost bool, jsonPost bool) map[string]string {
+// Profile returns the browser profile of the generator
+func (h *HeaderGenerator) Profile() Profile {
+	return h.profile
+}
+
+// GenerateGeneralRequestHeaders generate headers to help evade bot checks for a request to
+// target, only set origin if cross-origin post request, or ajax request
+func (h *HeaderGenerator) GenerateGeneralRequestHeaders(target, origin, referer string, formEncodedPost bool, jsonPost bool) map[string]string {
 	headers := make(map[string]string)
-	headers["User-Agent"] = h.getRandomUserAgent()
+	headers["User-Agent"] = h.profile.UserAgent
+	headers["Accept-Language"] = h.profile.AcceptLanguage
+	headers["Accept-Encoding"] = acceptEncoding
+	for key, value := range h.profile.ClientHints {
+		headers[key] = value
+	}
+
+	headers["Sec-Fetch-Site"] = fetchSite(target, referer)
+	if origin != "" || jsonPost {
+		// script request
+		headers["Accept"] = "*/*"
+		headers["Sec-Fetch-Mode"] = "cors"
+		headers["Sec-Fetch-Dest"] = "empty"
+	} else {
+		// page navigation or form submission
+		headers["Accept"] = h.profile.Accept
+		headers["Sec-Fetch-Mode"] = "navigate"
+		headers["Sec-Fetch-Dest"] = "document"
+		headers["Sec-Fetch-User"] = "?1"
+		headers["Upgrade-Insecure-Requests"] = "1"
+	}
+
 	if origin != "" {
 		headers["Origin"] = origin
 	}
@@ -43,3 +71,32 @@ func (h *HeaderGenerator) GenerateGeneralRequestHeaders(origin, referer string,
 	}
 	return headers
 }
+
+// fetchSite returns the Sec-Fetch-Site a browser sends for a request to target coming from
+// the page referer, "none" for requests typed into the address bar
+func fetchSite(target, referer string) string {
+	if referer == "" {
+		return "none"
+	}
+	from, err := url.Parse(referer)
+	if err != nil {
+		return "cross-site"
+	}
+	to, err := url.Parse(target)
+	if err != nil {
+		return "cross-site"
+	}
+	if from.Scheme == to.Scheme && from.Host == to.Host {
+		return "same-origin"
+	}
+
+	fromSite, err := publicsuffix.EffectiveTLDPlusOne(from.Hostname())
+	if err != nil {
+		return "cross-site"
+	}
+	toSite, err := publicsuffix.EffectiveTLDPlusOne(to.Hostname())
+	if err != nil || from.Scheme != to.Scheme || fromSite != toSite {
+		return "cross-site"
+	}
+	return "same-site"
+}
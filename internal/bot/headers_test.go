@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"testing"
+)
+
+// TestHeaderGenerator_StableProfile tests that a generator keeps its browser for all requests
+func TestHeaderGenerator_StableProfile(t *testing.T) {
+	h := NewHeaderGenerator()
+	first := h.GenerateGeneralRequestHeaders("https://a.de/", "", "", false, false)
+	for i := 0; i < 20; i++ {
+		headers := h.GenerateGeneralRequestHeaders("https://a.de/", "", "", false, false)
+		if headers["User-Agent"] != first["User-Agent"] || headers["sec-ch-ua"] != first["sec-ch-ua"] {
+			t.Fatalf("headers changed between requests: %q, want %q", headers["User-Agent"], first["User-Agent"])
+		}
+	}
+}
+
+// TestHeaderGenerator_Profiles tests that the headers of every profile fit its browser
+func TestHeaderGenerator_Profiles(t *testing.T) {
+	for _, family := range []Family{Chrome, Firefox, Safari, Mobile} {
+		if len(Profiles(family)) == 0 {
+			t.Errorf("no profiles for %s", family)
+		}
+	}
+
+	for _, profile := range profiles {
+		t.Run(profile.UserAgent, func(t *testing.T) {
+			headers := NewHeaderGeneratorFor(profile).GenerateGeneralRequestHeaders("https://a.de/", "", "", false, false)
+			chromium := headers["sec-ch-ua"] != ""
+			if want := profile.Family == Chrome || profile.ClientHints != nil; chromium != want {
+				t.Errorf("sec-ch-ua sent = %v, want %v", chromium, want)
+			}
+			if profile.Family == Firefox || profile.Family == Safari {
+				if chromium {
+					t.Errorf("%s sends Chromium client hints", profile.Family)
+				}
+			}
+			if chromium && (headers["sec-ch-ua-mobile"] == "?1") != (profile.Family == Mobile) {
+				t.Errorf("sec-ch-ua-mobile = %s for %s", headers["sec-ch-ua-mobile"], profile.Family)
+			}
+			if headers["Accept-Encoding"] != acceptEncoding {
+				t.Errorf("Accept-Encoding = %q, want %q", headers["Accept-Encoding"], acceptEncoding)
+			}
+			if headers["Sec-Fetch-Mode"] != "navigate" || headers["Accept"] != profile.Accept {
+				t.Errorf("navigation headers = %q %q", headers["Sec-Fetch-Mode"], headers["Accept"])
+			}
+		})
+	}
+}
+
+// TestHeaderGenerator_RequestKinds tests the headers of navigations, form and ajax posts
+func TestHeaderGenerator_RequestKinds(t *testing.T) {
+	h := NewHeaderGeneratorFor(Profiles(Firefox)[0])
+
+	tests := []struct {
+		name            string
+		target          string
+		origin, referer string
+		form, json      bool
+		want            map[string]string
+	}{
+		{"navigation", "https://a.de/", "", "", false, false, map[string]string{
+			"Sec-Fetch-Mode": "navigate", "Sec-Fetch-Site": "none", "Upgrade-Insecure-Requests": "1"}},
+		{"form post", "https://a.de/", "", "", true, false, map[string]string{
+			"Sec-Fetch-Mode": "navigate", "Content-Type": "application/x-www-form-urlencoded"}},
+		{"ajax form post", "https://a.de/search", "https://a.de", "https://a.de", true, false, map[string]string{
+			"Sec-Fetch-Mode": "cors", "Sec-Fetch-Site": "same-origin", "Accept": "*/*", "Origin": "https://a.de"}},
+		{"json post", "https://a.de/", "", "", false, true, map[string]string{
+			"Sec-Fetch-Mode": "cors", "Accept": "application/json", "Content-Type": "application/json"}},
+		{"link from a subdomain", "https://www.a.de/", "", "https://shop.a.de/", false, false, map[string]string{
+			"Sec-Fetch-Site": "same-site", "Referer": "https://shop.a.de/"}},
+		{"link from another site", "https://a.de/", "", "https://www.google.de/", false, false, map[string]string{
+			"Sec-Fetch-Site": "cross-site"}},
+		{"link from plain http", "https://a.de/", "", "http://a.de/", false, false, map[string]string{
+			"Sec-Fetch-Site": "cross-site"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := h.GenerateGeneralRequestHeaders(tt.target, tt.origin, tt.referer, tt.form, tt.json)
+			for key, want := range tt.want {
+				if headers[key] != want {
+					t.Errorf("%s = %q, want %q", key, headers[key], want)
+				}
+			}
+		})
+	}
+}
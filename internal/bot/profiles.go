@@ -0,0 +1,90 @@
+package bot
+
+// Family groups browsers that send the same kind of headers
+type Family string
+
+const (
+	Chrome  Family = "chrome" // Chromium based, incl. Edge
+	Firefox Family = "firefox"
+	Safari  Family = "safari"
+	Mobile  Family = "mobile"
+)
+
+// Profile is a coherent set of headers of one browser
+type Profile struct {
+	Family         Family
+	UserAgent      string
+	Accept         string // for page navigations
+	AcceptLanguage string
+	ClientHints    map[string]string // sec-ch-ua headers, only sent by Chromium
+}
+
+const (
+	chromeAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"
+	otherAccept  = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+	chromeBrands = `"Not A(Brand";v="8", "Chromium";v="132", "Google Chrome";v="132"`
+)
+
+var profiles = []Profile{
+	{
+		Family:         Chrome,
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/132.0.0.0 Safari/537.36",
+		Accept:         chromeAccept,
+		AcceptLanguage: "de-DE,de;q=0.9,en-US;q=0.8,en;q=0.7",
+		ClientHints:    map[string]string{"sec-ch-ua": chromeBrands, "sec-ch-ua-mobile": "?0", "sec-ch-ua-platform": `"Windows"`},
+	},
+	{
+		Family:         Chrome,
+		UserAgent:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/132.0.0.0 Safari/537.36",
+		Accept:         chromeAccept,
+		AcceptLanguage: "de-DE,de;q=0.9,en-US;q=0.8,en;q=0.7",
+		ClientHints:    map[string]string{"sec-ch-ua": chromeBrands, "sec-ch-ua-mobile": "?0", "sec-ch-ua-platform": `"macOS"`},
+	},
+	{
+		Family:         Chrome,
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/132.0.0.0 Safari/537.36 Edg/132.0.0.0",
+		Accept:         chromeAccept,
+		AcceptLanguage: "de,de-DE;q=0.9,en;q=0.8,en-GB;q=0.7,en-US;q=0.6",
+		ClientHints: map[string]string{
+			"sec-ch-ua":          `"Not A(Brand";v="8", "Chromium";v="132", "Microsoft Edge";v="132"`,
+			"sec-ch-ua-mobile":   "?0",
+			"sec-ch-ua-platform": `"Windows"`,
+		},
+	},
+	{
+		Family:         Firefox,
+		UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:134.0) Gecko/20100101 Firefox/134.0",
+		Accept:         otherAccept,
+		AcceptLanguage: "de,en-US;q=0.7,en;q=0.3",
+	},
+	{
+		Family:         Safari,
+		UserAgent:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.2 Safari/605.1.15",
+		Accept:         otherAccept,
+		AcceptLanguage: "de-DE,de;q=0.9",
+	},
+	{
+		Family:         Mobile,
+		UserAgent:      "Mozilla/5.0 (iPhone; CPU iPhone OS 18_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.2 Mobile/15E148 Safari/604.1",
+		Accept:         otherAccept,
+		AcceptLanguage: "de-DE,de;q=0.9",
+	},
+	{
+		Family:         Mobile,
+		UserAgent:      "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/132.0.0.0 Mobile Safari/537.36",
+		Accept:         chromeAccept,
+		AcceptLanguage: "de-DE,de;q=0.9,en-US;q=0.8,en;q=0.7",
+		ClientHints:    map[string]string{"sec-ch-ua": chromeBrands, "sec-ch-ua-mobile": "?1", "sec-ch-ua-platform": `"Android"`},
+	},
+}
+
+// Profiles returns the profiles of a browser family
+func Profiles(family Family) []Profile {
+	var result []Profile
+	for _, p := range profiles {
+		if p.Family == family {
+			result = append(result, p)
+		}
+	}
+	return result
+}
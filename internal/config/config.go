@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 // telegram bot config
 var (
@@ -13,6 +16,127 @@ const (
 	BaseURL          = "https://api.telegram.org"
 )
 
+// failed requests are retried with exponential backoff, POSTs only if RetryPOST is set
+const (
+	RetryAttempts  = 3
+	RetryBaseDelay = 2 * time.Second
+	RetryMaxDelay  = 15 * time.Second
+	RetryPOST      = true // the companies' search POSTs have no side effects
+)
+
+// a scraper pauses for BreakerCooldown after BreakerThreshold consecutive failed requests
+// to the same host
+const (
+	BreakerThreshold = 5
+	BreakerCooldown  = 10 * time.Minute
+)
+
+// requests to a host are spaced RateLimitInterval apart on average with up to
+// RateLimitBurst at once, shared by all scrapers
+const (
+	RateLimitInterval = 2 * time.Second
+	RateLimitBurst    = 2
+)
+
+// RateLimitIntervals overrides RateLimitInterval for the host of a company
+var RateLimitIntervals = map[string]time.Duration{
+	"Howoge": 5 * time.Second,
+}
+
+// SessionLandingPages gives scrapers of Typo3 sites their own cookie session, warmed up
+// by visiting the landing page first and renewed when the site answers 403
+var SessionLandingPages = map[string]string{
+	"Howoge": "https://www.howoge.de/immobiliensuche/wohnungssuche.html",
+	"Dewego": "https://www.degewo.de/immosuche",
+}
+
+// proxies from PROXY_URLS that fail are quarantined for ProxyQuarantine, all proxies are
+// checked by requesting ProxyCheckURL every ProxyCheckInterval. While all of them are
+// quarantined requests fail, unless ProxyAllowDirect exposes the server's IP instead.
+const (
+	ProxyQuarantine    = 15 * time.Minute
+	ProxyCheckInterval = 5 * time.Minute
+	ProxyCheckURL      = "https://www.wbm.de/"
+	ProxyAllowDirect   = false
+)
+
+// operational alerts go to the admin chat TELEGRAM_ADMIN_CHAT_ID, or only to the log if it
+// is not set. The same alert is sent at most once per AdminDedupWindow and at most
+// AdminMaxAlerts per AdminThrottleWindow. A scraper is reported after AdminScrapeFailures
+// failed scrapes in a row.
+const (
+	AdminDedupWindow    = time.Hour
+	AdminMaxAlerts      = 10
+	AdminThrottleWindow = time.Hour
+	AdminScrapeFailures = 3
+)
+
+// a scraper is reported to the admin chat as broken after HealthEmptyScrapes empty scrapes
+// in a row although it had listings before, when HealthMissingFieldRatio of at least
+// HealthMinListings listings lack a price, size or zip code, or when its response cannot
+// be decoded. The first HealthSnippetBytes of the response are saved to HealthSnippetDir.
+const (
+	HealthEmptyScrapes      = 5
+	HealthMissingFieldRatio = 0.5
+	HealthMinListings       = 4
+	HealthSnippetDir        = "data/snippets"
+	HealthSnippetBytes      = 32 << 10
+)
+
+// MetricsAddr serves Prometheus metrics on /metrics, empty disables it
+const MetricsAddr = ":9090"
+
+// Timezone used for digests and quiet hours
+const Timezone = "Europe/Berlin"
+
+// digest schedule
+const (
+	DigestTime      = "20:00"
+	DigestWeeklyDay = time.Sunday
+)
+
+// notifications for already seen listings that start to match after a rent change, while
+// online or when they come back online
+const (
+	NotifyPriceChanges = true
+	NotifyRelisted     = false
+)
+
+// DuplicateWindow is how long a flat is remembered to suppress notifications for re-posts
+// and listings of the same flat by other companies
+const DuplicateWindow = 14 * 24 * time.Hour
+
+// StateDir keeps the seen listings of every scraper across restarts, one JSON file each
+const StateDir = "data/state"
+
+// HistoryFile keeps the new listings of the digest periods across restarts
+const HistoryFile = "data/state/history.json"
+
+// ArchiveFile is the bbolt database every scraped listing is archived in
+const ArchiveFile = "data/archive.db"
+
+// matched listings are written to ExportDir as <user>.csv and <user>.jsonl every
+// ExportInterval, an empty ExportDir disables the periodic export
+const (
+	ExportDir      = ""
+	ExportInterval = time.Hour
+)
+
+// StatsRetention is how long removed listings are kept for lifetime statistics
+const StatsRetention = 90 * 24 * time.Hour
+
+// GeocodeDataFile is the offline address dataset (street,housenumber,zip,lat,lon),
+// listings are not geocoded if it is missing
+const GeocodeDataFile = "data/berlin_addresses.csv"
+
+// commute estimates use an unpacked GTFS feed (e.g. VBB) and assume a departure on a
+// typical working day
+const (
+	GTFSDir          = "data/vbb-gtfs"
+	CommuteDeparture = "08:00"
+	CommuteWeekday   = time.Tuesday
+)
+
 // state urls
 const (
 	GewobagURL      = "https://www.gewobag.de/fuer-mietinteressentinnen/mietangebote/?objekttyp%5B%5D=wohnung&gesamtmiete_von=&gesamtmiete_bis=&gesamtflaeche_von=&gesamtflaeche_bis=&zimmer_von=&zimmer_bis=&sort-by="
@@ -31,9 +155,57 @@ var (
 		"12435", // alt-treptow
 		"10179", // mitte
 	}
+	// Bezirk or Ortsteil names, e.g. "Neukölln", accepted in addition to ZipCodes
+	Districts = []string{}
+
 	MinWarm = 400
 	MaxWarm = 1000
 	MinSqm  = 40
 	MaxSqm  = 80
-	Wbs     = false
+	Wbs     = false // only listings that require a WBS
+
+	// the user's own WBS, a listing requiring one only matches if HasWbs is set
+	HasWbs          = false
+	WbsLevel        = 0 // income level on the certificate, e.g. 140 for WBS 140; 0 if not stated
+	WbsSpecialNeeds = false
+
+	// MaxWarmPerSqm limits the warm rent per m², 0 disables
+	MaxWarmPerSqm = 0.0
+	// NebenkostenPerSqm estimates the warm rent from the cold rent and vice versa when a
+	// company lists only one of them
+	NebenkostenPerSqm = 3.0
+
+	// non-urgent listings are held back between QuietHoursStart and QuietHoursEnd, e.g.
+	// "23:00" and "07:00", empty disables quiet hours
+	QuietHoursStart = ""
+	QuietHoursEnd   = ""
+	UrgentMaxWarm   = 700
+
+	// near match tolerances, e.g. 5.0, 3 and neighbouring zip codes like "12057", all zero
+	// disables near match notifications
+	PriceTolerancePercent = 0.0
+	SqmTolerance          = 0
+	NeighbourZipCodes     = []string{}
+
+	// geographic filters on geocoded listings, a radius of 0 and an empty file disable them
+	SearchCenterLat = 52.4870
+	SearchCenterLon = 13.4245
+	SearchRadiusKm  = 0.0
+	SearchAreaFile  = "" // GeoJSON Polygon, MultiPolygon, Feature or FeatureCollection
+
+	// commute by public transport, an empty name disables it
+	CommuteName       = ""
+	CommuteLat        = 52.5125
+	CommuteLon        = 13.3900
+	CommuteMaxMinutes = 40
+
+	// scoring preferences
+	TargetPricePerSqm  = 14.0
+	MinRooms           = 2.0
+	PreferredDistricts = []string{"Neukölln", "Kreuzberg", "Friedrichshain"}
+	MinFloor           = 1
+
+	// keyword filters on listing title and description
+	ExcludeKeywords = []string{"Senioren", "Tausch", "Gewerbe", "nur für Studierende"}
+	ExcludePatterns = []string{`\bbefristet\b`} // but not "unbefristet"
 )
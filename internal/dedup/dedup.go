@@ -0,0 +1,137 @@
+// Package dedup recognises the same flat listed more than once, either re-posted under
+// a new ID or advertised by several companies.
+package dedup
+
+import (
+	"apartmenthunter/internal/normalize"
+	"apartmenthunter/internal/scraping/common"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Fingerprint identifies a flat independently of the company and listing ID
+type Fingerprint string
+
+// Source is one listing of a flat
+type Source struct {
+	Company string
+	ID      string
+	SeenAt  time.Time
+}
+
+type entry struct {
+	sources  []Source
+	lastSeen time.Time
+}
+
+// Detector remembers fingerprints for a window and reports listings of flats already seen
+type Detector struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[Fingerprint]*entry
+}
+
+// NewDetector creates a detector treating listings of the same flat within window as duplicates
+func NewDetector(window time.Duration) *Detector {
+	return &Detector{
+		window:  window,
+		now:     time.Now,
+		entries: make(map[Fingerprint]*entry),
+	}
+}
+
+// FingerprintOf builds a fingerprint from the normalised street and house number, the
+// size rounded to m², the warm rent rounded to 10 €, the rooms and the floor, so equal
+// flats in one building stay apart if their floor is known. Returns false if the listing
+// lacks the address, size or rent.
+func FingerprintOf(l common.Listing) (Fingerprint, bool) {
+	address := normalizeAddress(l.Address)
+	size, errSize := l.SizeValue()
+	rent, errRent := l.PriceValue()
+	if address == "" || errSize != nil || errRent != nil {
+		return "", false
+	}
+	rooms := ""
+	if value, err := l.RoomsValue(); err == nil && value > 0 {
+		rooms = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return Fingerprint(fmt.Sprintf("%s|%.0f|%.0f|%s|%s", address, math.Round(size), math.Round(rent/10)*10, rooms, l.Floor)), true
+}
+
+// Check records the listing and returns the other sources of the same flat seen within
+// the window. A listing is a duplicate if that list is not empty. listed holds the IDs
+// of the company's current scrape, listings of the same company still online are
+// different flats rather than re-posts.
+func (d *Detector) Check(l common.Listing, listed map[string]bool) ([]Source, bool) {
+	fingerprint, ok := FingerprintOf(l)
+	if !ok {
+		return nil, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.prune(now)
+
+	e, exists := d.entries[fingerprint]
+	if !exists {
+		e = &entry{}
+		d.entries[fingerprint] = e
+	}
+	e.lastSeen = now
+
+	var others []Source
+	known := false
+	for _, source := range e.sources {
+		if source.Company == l.Company && source.ID == l.ID {
+			known = true
+			continue
+		}
+		if source.Company == l.Company && listed[source.ID] {
+			continue
+		}
+		others = append(others, source)
+	}
+	if !known {
+		e.sources = append(e.sources, Source{Company: l.Company, ID: l.ID, SeenAt: now})
+	}
+	return others, len(others) > 0
+}
+
+// Key returns the source as "Company/ID", the key of the listing in the archive
+func (s Source) Key() string {
+	return s.Company + "/" + s.ID
+}
+
+// Size returns the number of flats remembered
+func (d *Detector) Size() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.entries)
+}
+
+// prune forgets flats not seen within the window
+func (d *Detector) prune(now time.Time) {
+	for fingerprint, e := range d.entries {
+		if now.Sub(e.lastSeen) > d.window {
+			delete(d.entries, fingerprint)
+		}
+	}
+}
+
+// normalizeAddress keeps street and house number, so "Weserstr. 5, 12047 Berlin" and
+// "Weserstraße 5, Neukölln, Berlin" compare equal
+func normalizeAddress(address string) string {
+	street := normalize.Street(strings.Split(address, ",")[0])
+	return nonAlnum.ReplaceAllString(street, "")
+}
@@ -0,0 +1,124 @@
+package dedup
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"testing"
+	"time"
+)
+
+// TestFingerprintOf tests that equivalent listings share a fingerprint
+func TestFingerprintOf(t *testing.T) {
+	base := common.Listing{Address: "Weserstraße 5, 12047 Berlin", Size: "60.5", Price: "800", Rooms: "2", Floor: "3"}
+
+	tests := []struct {
+		name    string
+		listing common.Listing
+		same    bool
+	}{
+		{"abbreviated street and other suffix", common.Listing{Address: "Weserstr. 5, Neukölln, Berlin", Size: "60,50", Price: "801,00", Rooms: "2,0", Floor: "3"}, true},
+		{"spelled out umlaut", common.Listing{Address: "Weserstrasse 5, Berlin", Size: "61", Price: "798", Rooms: "2", Floor: "3"}, true},
+		{"other house number", common.Listing{Address: "Weserstraße 7, 12047 Berlin", Size: "60.5", Price: "800", Rooms: "2", Floor: "3"}, false},
+		{"other size", common.Listing{Address: "Weserstraße 5, 12047 Berlin", Size: "75", Price: "800", Rooms: "2", Floor: "3"}, false},
+		{"other rent", common.Listing{Address: "Weserstraße 5, 12047 Berlin", Size: "60.5", Price: "950", Rooms: "2", Floor: "3"}, false},
+		{"other floor", common.Listing{Address: "Weserstraße 5, 12047 Berlin", Size: "60.5", Price: "800", Rooms: "2", Floor: "4"}, false},
+		{"other rooms", common.Listing{Address: "Weserstraße 5, 12047 Berlin", Size: "60.5", Price: "800", Rooms: "2,5", Floor: "3"}, false},
+	}
+
+	want, ok := FingerprintOf(base)
+	if !ok {
+		t.Fatal("FingerprintOf() should fingerprint the base listing")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FingerprintOf(tt.listing)
+			if !ok {
+				t.Fatal("FingerprintOf() should succeed")
+			}
+			if (got == want) != tt.same {
+				t.Errorf("FingerprintOf() = %q, base %q, want same = %v", got, want, tt.same)
+			}
+		})
+	}
+
+	if _, ok := FingerprintOf(common.Listing{Address: "Weserstraße 5", Price: "800"}); ok {
+		t.Error("FingerprintOf() should fail without a size")
+	}
+}
+
+// TestDetector_Check tests duplicate detection across companies and re-posts
+func TestDetector_Check(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	d := NewDetector(24 * time.Hour)
+	d.now = func() time.Time { return now }
+
+	original := common.Listing{ID: "1", Company: "Howoge", Address: "Weserstraße 5, 12047 Berlin", Size: "60", Price: "800"}
+	repost := common.Listing{ID: "2", Company: "Howoge", Address: "Weserstr. 5, Berlin", Size: "60", Price: "800"}
+	portal := common.Listing{ID: "abc", Company: "Portal", Address: "Weserstraße 5, 12047 Berlin", Size: "60.2", Price: "799"}
+
+	if _, dup := d.Check(original, map[string]bool{"1": true}); dup {
+		t.Error("first listing should not be a duplicate")
+	}
+	if _, dup := d.Check(original, map[string]bool{"1": true}); dup {
+		t.Error("the same listing seen again should not be a duplicate")
+	}
+
+	now = now.Add(time.Hour)
+	others, dup := d.Check(repost, map[string]bool{"2": true})
+	if !dup || len(others) != 1 || others[0].ID != "1" {
+		t.Errorf("repost = %v, %v, want duplicate of listing 1", others, dup)
+	}
+
+	others, dup = d.Check(portal, map[string]bool{"abc": true})
+	if !dup || len(others) != 2 || others[0].Key() != "Howoge/1" || others[1].Key() != "Howoge/2" {
+		t.Errorf("portal listing = %v, %v, want duplicate of both Howoge listings in order", others, dup)
+	}
+}
+
+// TestDetector_SameCompany tests that equal flats listed by one company at the same time
+// are not duplicates
+func TestDetector_SameCompany(t *testing.T) {
+	d := NewDetector(24 * time.Hour)
+	first := common.Listing{ID: "1", Company: "WBM", Address: "Weserstraße 5", Size: "60", Price: "800", Rooms: "2"}
+	second := common.Listing{ID: "2", Company: "WBM", Address: "Weserstraße 5", Size: "60", Price: "800", Rooms: "2"}
+	listed := map[string]bool{"1": true, "2": true}
+
+	_, _ = d.Check(first, listed)
+	if others, dup := d.Check(second, listed); dup {
+		t.Errorf("second flat = %v, want no duplicate while both are listed", others)
+	}
+	if others, dup := d.Check(common.Listing{ID: "3", Company: "WBM", Address: "Weserstraße 5", Size: "60", Price: "800", Rooms: "2"},
+		map[string]bool{"2": true, "3": true}); !dup || len(others) != 1 || others[0].ID != "1" {
+		t.Errorf("re-post = %v, %v, want duplicate of the listing no longer online", others, dup)
+	}
+}
+
+// TestDetector_Window tests that flats are forgotten after the window
+func TestDetector_Window(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	d := NewDetector(24 * time.Hour)
+	d.now = func() time.Time { return now }
+
+	_, _ = d.Check(common.Listing{ID: "1", Company: "WBM", Address: "Weserstraße 5", Size: "60", Price: "800"}, nil)
+	now = now.Add(25 * time.Hour)
+
+	if _, dup := d.Check(common.Listing{ID: "2", Company: "WBM", Address: "Weserstraße 5", Size: "60", Price: "800"}, nil); dup {
+		t.Error("listing after the window should not be a duplicate")
+	}
+	if d.Size() != 1 {
+		t.Errorf("Size() = %d, want 1 after pruning", d.Size())
+	}
+}
+
+// TestDetector_Unfingerprintable tests listings without enough data
+func TestDetector_Unfingerprintable(t *testing.T) {
+	d := NewDetector(time.Hour)
+	listing := common.Listing{ID: "1", Company: "WBM", Price: "auf Anfrage"}
+
+	_, _ = d.Check(listing, nil)
+	if _, dup := d.Check(common.Listing{ID: "2", Company: "WBM", Price: "auf Anfrage"}, nil); dup {
+		t.Error("listings without fingerprint should never be duplicates")
+	}
+	if d.Size() != 0 {
+		t.Errorf("Size() = %d, want 0", d.Size())
+	}
+}
@@ -0,0 +1,208 @@
+package digest
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/users"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxMisses limits how many near misses are listed in a digest
+const maxMisses = 3
+
+// Miss is a listing that matched every criterion except the maximum rent
+type Miss struct {
+	Listing    common.Listing
+	OverBudget int
+}
+
+// Match is a listing that passed the user's filters, with its score if the user has preferences
+type Match struct {
+	Listing common.Listing
+	Score   float64
+	Scored  bool
+}
+
+// Summary holds the digest of one period for one user
+type Summary struct {
+	Title            string
+	From, To         time.Time
+	NewPerCompany    map[string]int
+	Matches          []Match // best score first
+	ClosestMisses    []Miss
+	MedianRentPerSqm float64
+	RentSamples      int
+}
+
+// Total returns the number of new listings across all companies
+func (s *Summary) Total() int {
+	total := 0
+	for _, count := range s.NewPerCompany {
+		total += count
+	}
+	return total
+}
+
+// Build summarises the entries of a period from the perspective of a single user
+func Build(title string, entries []Entry, user *users.UserConfig, from, to time.Time) *Summary {
+	summary := &Summary{
+		Title:         title,
+		From:          from,
+		To:            to,
+		NewPerCompany: make(map[string]int),
+	}
+
+	var (
+		matches     []common.Listing
+		rentsPerSqm []float64
+	)
+	for _, e := range entries {
+		listing := e.Listing
+		summary.NewPerCompany[listing.Company]++
+
+		if listing.MatchUserConfig(user) {
+			matches = append(matches, listing)
+		} else if over, ok := overBudget(listing, user); ok {
+			summary.ClosestMisses = append(summary.ClosestMisses, Miss{Listing: listing, OverBudget: over})
+		}
+
+		if inZipCodes(listing, user.ZipCodes) {
+			if perSqm, ok := rentPerSqm(listing); ok {
+				rentsPerSqm = append(rentsPerSqm, perSqm)
+			}
+		}
+	}
+
+	common.RankByScore(matches, user)
+	for _, l := range matches {
+		score, scored := l.Score(user)
+		summary.Matches = append(summary.Matches, Match{Listing: l, Score: score, Scored: scored})
+	}
+
+	sort.SliceStable(summary.ClosestMisses, func(i, j int) bool {
+		return summary.ClosestMisses[i].OverBudget < summary.ClosestMisses[j].OverBudget
+	})
+	if len(summary.ClosestMisses) > maxMisses {
+		summary.ClosestMisses = summary.ClosestMisses[:maxMisses]
+	}
+
+	summary.MedianRentPerSqm = median(rentsPerSqm)
+	summary.RentSamples = len(rentsPerSqm)
+	return summary
+}
+
+// overBudget reports by how much a listing exceeds the user's maximum rent,
+// but only if it would have matched without the rent limit
+func overBudget(listing common.Listing, user *users.UserConfig) (int, bool) {
+	if user.MaxPrice == 0 {
+		return 0, false
+	}
+	price, err := listing.PriceValue()
+	if err != nil || int(price) <= user.MaxPrice {
+		return 0, false
+	}
+
+	relaxed := *user
+	relaxed.MaxPrice = 0
+	if !listing.MatchUserConfig(&relaxed) {
+		return 0, false
+	}
+	return int(price) - user.MaxPrice, true
+}
+
+func inZipCodes(listing common.Listing, zipCodes []string) bool {
+	if len(zipCodes) == 0 {
+		return true
+	}
+	for _, zip := range zipCodes {
+		if listing.ZipCode == zip {
+			return true
+		}
+	}
+	return false
+}
+
+func rentPerSqm(listing common.Listing) (float64, bool) {
+	price, err := listing.PriceValue()
+	if err != nil {
+		return 0, false
+	}
+	size, err := listing.SizeValue()
+	if err != nil || size == 0 {
+		return 0, false
+	}
+	return price / size, true
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// BuildHTML renders a summary as a telegram HTML message
+func BuildHTML(s *Summary) string {
+	if s == nil {
+		return "Data not provided"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>%s Digest</b> (%s – %s)\n\n",
+		html.EscapeString(s.Title), s.From.Format("02.01. 15:04"), s.To.Format("02.01. 15:04"))
+
+	fmt.Fprintf(&b, "<b>New listings:</b> %d\n", s.Total())
+	companies := make([]string, 0, len(s.NewPerCompany))
+	for company := range s.NewPerCompany {
+		companies = append(companies, company)
+	}
+	sort.Strings(companies)
+	for _, company := range companies {
+		fmt.Fprintf(&b, "%s: %d\n", html.EscapeString(company), s.NewPerCompany[company])
+	}
+
+	fmt.Fprintf(&b, "\n<b>Matches:</b> %d\n", len(s.Matches))
+	for _, m := range s.Matches {
+		fmt.Fprintf(&b, "%s – %s m², %s €", listingLink(m.Listing), html.EscapeString(m.Listing.Size), html.EscapeString(m.Listing.Price))
+		if m.Scored {
+			fmt.Fprintf(&b, ", score %.0f", m.Score)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(s.ClosestMisses) > 0 {
+		b.WriteString("\n<b>Closest misses:</b>\n")
+		for _, m := range s.ClosestMisses {
+			fmt.Fprintf(&b, "%s – %d € over budget (%s €)\n", listingLink(m.Listing), m.OverBudget, html.EscapeString(m.Listing.Price))
+		}
+	}
+
+	if s.RentSamples > 0 {
+		fmt.Fprintf(&b, "\n<b>Median rent in your zip codes:</b> %.2f €/m² (%d listings)", s.MedianRentPerSqm, s.RentSamples)
+	} else {
+		b.WriteString("\n<b>Median rent in your zip codes:</b> -")
+	}
+
+	return b.String()
+}
+
+func listingLink(l common.Listing) string {
+	address := l.Address
+	if address == "" {
+		address = l.ID
+	}
+	link := l.URL
+	if link == "" {
+		link = "#"
+	}
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(link), html.EscapeString(address))
+}
@@ -0,0 +1,186 @@
+package digest
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/users"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testUser() *users.UserConfig {
+	return &users.UserConfig{
+		ZipCodes: []string{"12043", "12045"},
+		MinPrice: 500,
+		MaxPrice: 1000,
+		MinSqm:   40,
+		MaxSqm:   80,
+	}
+}
+
+func entriesAt(seenAt time.Time, listings ...common.Listing) []Entry {
+	var entries []Entry
+	for _, l := range listings {
+		entries = append(entries, Entry{Listing: l, SeenAt: seenAt})
+	}
+	return entries
+}
+
+// TestBuild tests the digest summary calculation
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+	entries := entriesAt(now.Add(-time.Hour),
+		common.Listing{ID: "1", Company: "Howoge", ZipCode: "12043", Price: "800", Size: "50"},
+		common.Listing{ID: "2", Company: "Howoge", ZipCode: "12045", Price: "1020", Size: "60"},
+		common.Listing{ID: "3", Company: "Gewobag", ZipCode: "12043", Price: "1005", Size: "67"},
+		common.Listing{ID: "4", Company: "Gewobag", ZipCode: "10115", Price: "900", Size: "60"},
+		common.Listing{ID: "5", Company: "WBM", ZipCode: "12043", Price: "1500", Size: "100"},
+	)
+
+	summary := Build("Daily", entries, testUser(), now.Add(-DailyPeriod), now)
+
+	if summary.Total() != 5 {
+		t.Errorf("Total() = %d, want 5", summary.Total())
+	}
+	wantCounts := map[string]int{"Howoge": 2, "Gewobag": 2, "WBM": 1}
+	for company, want := range wantCounts {
+		if got := summary.NewPerCompany[company]; got != want {
+			t.Errorf("NewPerCompany[%s] = %d, want %d", company, got, want)
+		}
+	}
+
+	if len(summary.Matches) != 1 || summary.Matches[0].Listing.ID != "1" {
+		t.Errorf("Matches = %v, want only listing 1", summary.Matches)
+	}
+
+	// listing 5 is over budget but also too large, listing 4 is outside the zip codes
+	if len(summary.ClosestMisses) != 2 {
+		t.Fatalf("ClosestMisses = %v, want 2 entries", summary.ClosestMisses)
+	}
+	if summary.ClosestMisses[0].Listing.ID != "3" || summary.ClosestMisses[0].OverBudget != 5 {
+		t.Errorf("closest miss = %+v, want listing 3 with 5 € over budget", summary.ClosestMisses[0])
+	}
+	if summary.ClosestMisses[1].Listing.ID != "2" || summary.ClosestMisses[1].OverBudget != 20 {
+		t.Errorf("second miss = %+v, want listing 2 with 20 € over budget", summary.ClosestMisses[1])
+	}
+
+	// rents per m² in 12043/12045: 16, 17, 15, 15 -> median 15.5
+	if summary.RentSamples != 4 {
+		t.Errorf("RentSamples = %d, want 4", summary.RentSamples)
+	}
+	if summary.MedianRentPerSqm != 15.5 {
+		t.Errorf("MedianRentPerSqm = %.2f, want 15.50", summary.MedianRentPerSqm)
+	}
+}
+
+// TestBuild_MissesLimited tests that only the closest misses are kept
+func TestBuild_MissesLimited(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+	entries := entriesAt(now,
+		common.Listing{ID: "a", ZipCode: "12043", Price: "1100", Size: "60"},
+		common.Listing{ID: "b", ZipCode: "12043", Price: "1010", Size: "60"},
+		common.Listing{ID: "c", ZipCode: "12043", Price: "1050", Size: "60"},
+		common.Listing{ID: "d", ZipCode: "12043", Price: "1001", Size: "60"},
+	)
+
+	summary := Build("Daily", entries, testUser(), now.Add(-DailyPeriod), now)
+
+	if len(summary.ClosestMisses) != maxMisses {
+		t.Fatalf("len(ClosestMisses) = %d, want %d", len(summary.ClosestMisses), maxMisses)
+	}
+	wantOrder := []string{"d", "b", "c"}
+	for i, id := range wantOrder {
+		if summary.ClosestMisses[i].Listing.ID != id {
+			t.Errorf("ClosestMisses[%d] = %s, want %s", i, summary.ClosestMisses[i].Listing.ID, id)
+		}
+	}
+}
+
+// TestBuild_NoMaxPrice tests that no misses are reported without a rent limit
+func TestBuild_NoMaxPrice(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+	user := testUser()
+	user.MaxPrice = 0
+	entries := entriesAt(now, common.Listing{ID: "1", ZipCode: "10115", Price: "1100", Size: "60"})
+
+	summary := Build("Daily", entries, user, now.Add(-DailyPeriod), now)
+
+	if len(summary.ClosestMisses) != 0 {
+		t.Errorf("ClosestMisses = %v, want none", summary.ClosestMisses)
+	}
+	if summary.RentSamples != 0 || summary.MedianRentPerSqm != 0 {
+		t.Errorf("median = %.2f over %d samples, want none", summary.MedianRentPerSqm, summary.RentSamples)
+	}
+}
+
+// TestBuildHTML tests the digest message rendering
+func TestBuildHTML(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+	entries := entriesAt(now,
+		common.Listing{ID: "1", Company: "Howoge", ZipCode: "12043", Price: "800", Size: "50", Address: "Weserstr. 1", URL: "https://example.com/1"},
+		common.Listing{ID: "2", Company: "Gewobag", ZipCode: "12043", Price: "1020", Size: "60", Address: "A & B Straße 2"},
+	)
+	result := BuildHTML(Build("Daily", entries, testUser(), now.Add(-DailyPeriod), now))
+
+	expectedElements := []string{
+		"<b>Daily Digest</b> (18.10. 20:00 – 19.10. 20:00)",
+		"<b>New listings:</b> 2",
+		"Gewobag: 1",
+		"Howoge: 1",
+		"<b>Matches:</b> 1",
+		`<a href="https://example.com/1">Weserstr. 1</a> – 50 m², 800 €`,
+		"<b>Closest misses:</b>",
+		`<a href="#">A &amp; B Straße 2</a> – 20 € over budget (1020 €)`,
+		"<b>Median rent in your zip codes:</b> 16.50 €/m² (2 listings)",
+	}
+	for _, elem := range expectedElements {
+		if !strings.Contains(result, elem) {
+			t.Errorf("BuildHTML(): %s expected in %s but not found", elem, result)
+		}
+	}
+}
+
+// TestBuildHTML_Empty tests rendering a period without listings
+func TestBuildHTML_Empty(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+	result := BuildHTML(Build("Weekly", nil, testUser(), now.Add(-WeeklyPeriod), now))
+
+	if !strings.Contains(result, "<b>New listings:</b> 0") {
+		t.Errorf("BuildHTML() should report zero listings, got %s", result)
+	}
+	if strings.Contains(result, "Closest misses") {
+		t.Errorf("BuildHTML() should omit misses section, got %s", result)
+	}
+	if !strings.HasSuffix(result, "<b>Median rent in your zip codes:</b> -") {
+		t.Errorf("BuildHTML() should show median placeholder, got %s", result)
+	}
+	if BuildHTML(nil) != "Data not provided" {
+		t.Errorf("BuildHTML(nil) = %q", BuildHTML(nil))
+	}
+}
+
+// TestBuild_MatchesRankedByScore tests that matches are ordered by the user's preferences
+func TestBuild_MatchesRankedByScore(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+	user := testUser()
+	user.Preferences = &users.Preferences{TargetPricePerSqm: 12, Weights: users.Weights{PricePerSqm: 1}}
+	entries := entriesAt(now,
+		common.Listing{ID: "expensive", ZipCode: "12043", Price: "900", Size: "50"},
+		common.Listing{ID: "cheap", ZipCode: "12043", Price: "600", Size: "50"},
+	)
+
+	summary := Build("Daily", entries, user, now.Add(-DailyPeriod), now)
+
+	if len(summary.Matches) != 2 {
+		t.Fatalf("len(Matches) = %d, want 2", len(summary.Matches))
+	}
+	if summary.Matches[0].Listing.ID != "cheap" || !summary.Matches[0].Scored {
+		t.Errorf("Matches[0] = %+v, want the cheap listing scored first", summary.Matches[0])
+	}
+	if summary.Matches[0].Score != 100 || summary.Matches[1].Score != 50 {
+		t.Errorf("scores = %.0f, %.0f, want 100, 50", summary.Matches[0].Score, summary.Matches[1].Score)
+	}
+	if !strings.Contains(BuildHTML(summary), "– 50 m², 600 €, score 100") {
+		t.Errorf("BuildHTML() should show the score, got %s", BuildHTML(summary))
+	}
+}
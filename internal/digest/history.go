@@ -0,0 +1,104 @@
+package digest
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is a listing together with the time it was first seen
+type Entry struct {
+	Listing common.Listing `json:"listing"`
+	SeenAt  time.Time      `json:"seen_at"`
+}
+
+// History keeps newly scraped listings for a limited retention period (thread-safe)
+type History struct {
+	mu        sync.RWMutex
+	entries   []Entry
+	retention time.Duration
+}
+
+func NewHistory(retention time.Duration) *History {
+	return &History{
+		retention: retention,
+	}
+}
+
+// Record stores a listing and drops entries older than the retention period
+func (h *History) Record(listing common.Listing, seenAt time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, Entry{Listing: listing, SeenAt: seenAt})
+	h.prune(seenAt.Add(-h.retention))
+}
+
+// Between returns all entries seen in [from, to)
+func (h *History) Between(from, to time.Time) []Entry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var result []Entry
+	for _, e := range h.entries {
+		if !e.SeenAt.Before(from) && e.SeenAt.Before(to) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Save writes the entries to path, replacing the file atomically, so the digests survive
+// a restart
+func (h *History) Save(path string) error {
+	// the write lock also keeps scrapers from saving concurrently
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	data, err := json.Marshal(h.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadHistory reads a history written by Save, dropping entries seen before now minus
+// the retention period
+func LoadHistory(path string, retention time.Duration, now time.Time) (*History, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	h := NewHistory(retention)
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	h.prune(now.Add(-retention))
+	return h, nil
+}
+
+func (h *History) Size() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.entries)
+}
+
+// prune expects the lock to be held
+func (h *History) prune(cutoff time.Time) {
+	kept := h.entries[:0]
+	for _, e := range h.entries {
+		if !e.SeenAt.Before(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	h.entries = kept
+}
@@ -0,0 +1,70 @@
+package digest
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistory_RecordAndBetween(t *testing.T) {
+	h := NewHistory(WeeklyPeriod)
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	h.Record(common.Listing{ID: "1"}, start)
+	h.Record(common.Listing{ID: "2"}, start.Add(time.Hour))
+	h.Record(common.Listing{ID: "3"}, start.Add(2*time.Hour))
+
+	entries := h.Between(start, start.Add(2*time.Hour))
+	if len(entries) != 2 {
+		t.Fatalf("Between() returned %d entries, want 2", len(entries))
+	}
+	if entries[0].Listing.ID != "1" || entries[1].Listing.ID != "2" {
+		t.Errorf("Between() = %v, want listings 1 and 2", entries)
+	}
+}
+
+func TestHistory_Retention(t *testing.T) {
+	h := NewHistory(DailyPeriod)
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	h.Record(common.Listing{ID: "old"}, start)
+	h.Record(common.Listing{ID: "new"}, start.Add(DailyPeriod+time.Minute))
+
+	if h.Size() != 1 {
+		t.Fatalf("history size should be 1 after pruning, got %d", h.Size())
+	}
+	entries := h.Between(start, start.Add(2*DailyPeriod))
+	if len(entries) != 1 || entries[0].Listing.ID != "new" {
+		t.Errorf("Between() = %v, want only the new listing", entries)
+	}
+}
+
+func TestHistory_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "history.json")
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	h := NewHistory(DailyPeriod)
+	h.Record(common.Listing{ID: "old", Company: "WBM"}, start)
+	h.Record(common.Listing{ID: "new", Company: "WBM", Price: "800", RentType: common.RentWarm}, start.Add(20*time.Hour))
+	if err := h.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	loaded, err := LoadHistory(path, DailyPeriod, start.Add(30*time.Hour))
+	if err != nil {
+		t.Fatalf("LoadHistory() unexpected error: %v", err)
+	}
+	entries := loaded.Between(start, start.Add(2*DailyPeriod))
+	if len(entries) != 1 || entries[0].Listing.ID != "new" || entries[0].Listing.RentType != common.RentWarm {
+		t.Fatalf("Between() = %+v, want only the entry within the retention period", entries)
+	}
+	if !entries[0].SeenAt.Equal(start.Add(20 * time.Hour)) {
+		t.Errorf("SeenAt = %v, want the time it was recorded", entries[0].SeenAt)
+	}
+
+	if _, err := LoadHistory(filepath.Join(t.TempDir(), "missing.json"), DailyPeriod, start); !os.IsNotExist(err) {
+		t.Errorf("LoadHistory() of a missing file = %v, want not exist", err)
+	}
+}
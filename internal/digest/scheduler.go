@@ -0,0 +1,108 @@
+package digest
+
+import (
+	"apartmenthunter/internal/users"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	DailyPeriod  = 24 * time.Hour
+	WeeklyPeriod = 7 * 24 * time.Hour
+)
+
+type period struct {
+	title  string
+	length time.Duration
+}
+
+// Sender delivers an HTML message to a chat, implemented by telegram.Client
+type Sender interface {
+	SendMessageTo(ctx context.Context, chatID, htmlMessage string) error
+}
+
+// Scheduler sends a daily digest to every user at a fixed time of day, and a
+// weekly digest on the configured weekday
+type Scheduler struct {
+	history   *History
+	users     *users.FilterConfig
+	sender    Sender
+	hour      int
+	minute    int
+	weeklyDay time.Weekday
+	location  *time.Location
+	now       func() time.Time
+}
+
+// NewScheduler creates a scheduler firing at "HH:MM" in the given location
+func NewScheduler(history *History, filterConfig *users.FilterConfig, sender Sender, at string, weeklyDay time.Weekday, location *time.Location) (*Scheduler, error) {
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest time %q: %w", at, err)
+	}
+	if location == nil {
+		location = time.Local
+	}
+
+	return &Scheduler{
+		history:   history,
+		users:     filterConfig,
+		sender:    sender,
+		hour:      t.Hour(),
+		minute:    t.Minute(),
+		weeklyDay: weeklyDay,
+		location:  location,
+		now:       time.Now,
+	}, nil
+}
+
+// Run blocks until ctx is cancelled, sending digests at every scheduled time
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		next := s.nextRun(s.now())
+		log.Printf("[digest] next digest at %s", next.Format(time.RFC1123))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Println("[digest] scheduler stopped")
+			return
+		case <-timer.C:
+			s.SendDigests(ctx, next)
+		}
+	}
+}
+
+// SendDigests sends the daily digest ending at now to every user, plus the weekly
+// digest if now falls on the weekly digest day
+func (s *Scheduler) SendDigests(ctx context.Context, now time.Time) {
+	periods := []period{{"Daily", DailyPeriod}}
+	if now.In(s.location).Weekday() == s.weeklyDay {
+		periods = append(periods, period{"Weekly", WeeklyPeriod})
+	}
+
+	for _, p := range periods {
+		from := now.Add(-p.length)
+		entries := s.history.Between(from, now)
+		for i := range s.users.Users {
+			user := &s.users.Users[i]
+			summary := Build(p.title, entries, user, from.In(s.location), now.In(s.location))
+			if err := s.sender.SendMessageTo(ctx, user.ChatID, BuildHTML(summary)); err != nil {
+				log.Printf("[digest] failed to send %s digest to user %q: %v", p.title, user.UserID, err)
+			}
+		}
+	}
+}
+
+// nextRun returns the first scheduled time strictly after now
+func (s *Scheduler) nextRun(now time.Time) time.Time {
+	local := now.In(s.location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.hour, s.minute, 0, 0, s.location)
+	if !next.After(local) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, s.hour, s.minute, 0, 0, s.location)
+	}
+	return next
+}
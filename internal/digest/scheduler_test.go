@@ -0,0 +1,110 @@
+package digest
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/users"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sentMessage struct {
+	chatID, html string
+}
+
+type stubSender struct {
+	sent []sentMessage
+	err  error
+}
+
+func (s *stubSender) SendMessageTo(_ context.Context, chatID, htmlMessage string) error {
+	s.sent = append(s.sent, sentMessage{chatID, htmlMessage})
+	return s.err
+}
+
+func TestNewScheduler_InvalidTime(t *testing.T) {
+	_, err := NewScheduler(NewHistory(WeeklyPeriod), &users.FilterConfig{}, &stubSender{}, "25:99", time.Sunday, time.UTC)
+	if err == nil {
+		t.Error("NewScheduler() error = nil, want error for invalid time")
+	}
+}
+
+func TestScheduler_NextRun(t *testing.T) {
+	s, err := NewScheduler(NewHistory(WeeklyPeriod), &users.FilterConfig{}, &stubSender{}, "20:00", time.Sunday, time.UTC)
+	if err != nil {
+		t.Fatalf("NewScheduler() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"before digest time", time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)},
+		{"exactly at digest time", time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC), time.Date(2026, 10, 20, 20, 0, 0, 0, time.UTC)},
+		{"after digest time", time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC), time.Date(2026, 10, 20, 20, 0, 0, 0, time.UTC)},
+		{"end of month", time.Date(2026, 10, 31, 21, 0, 0, 0, time.UTC), time.Date(2026, 11, 1, 20, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.nextRun(tt.now); !got.Equal(tt.want) {
+				t.Errorf("nextRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_SendDigests(t *testing.T) {
+	history := NewHistory(WeeklyPeriod)
+	filterConfig := &users.FilterConfig{Users: []users.UserConfig{
+		{UserID: "a", ChatID: "chat-a"},
+		{UserID: "b"},
+	}}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantCount int
+	}{
+		{"weekday sends daily only", time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC), 2},          // Monday
+		{"weekly day sends daily and weekly", time.Date(2026, 10, 25, 20, 0, 0, 0, time.UTC), 4}, // Sunday
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &stubSender{}
+			s, _ := NewScheduler(history, filterConfig, sender, "20:00", time.Sunday, time.UTC)
+			history.Record(common.Listing{ID: tt.name, Company: "Howoge"}, tt.now.Add(-time.Hour))
+
+			s.SendDigests(context.Background(), tt.now)
+
+			if len(sender.sent) != tt.wantCount {
+				t.Fatalf("sent %d messages, want %d", len(sender.sent), tt.wantCount)
+			}
+			if sender.sent[0].chatID != "chat-a" || sender.sent[1].chatID != "" {
+				t.Errorf("chat ids = %q, %q, want chat-a and default", sender.sent[0].chatID, sender.sent[1].chatID)
+			}
+			if !strings.Contains(sender.sent[0].html, "Daily Digest") {
+				t.Errorf("first message should be the daily digest, got %s", sender.sent[0].html)
+			}
+			if tt.wantCount == 4 && !strings.Contains(sender.sent[2].html, "Weekly Digest") {
+				t.Errorf("third message should be the weekly digest, got %s", sender.sent[2].html)
+			}
+		})
+	}
+}
+
+func TestScheduler_SendDigests_ContinuesOnError(t *testing.T) {
+	sender := &stubSender{err: errors.New("telegram down")}
+	filterConfig := &users.FilterConfig{Users: []users.UserConfig{{UserID: "a"}, {UserID: "b"}}}
+	s, _ := NewScheduler(NewHistory(WeeklyPeriod), filterConfig, sender, "20:00", time.Sunday, time.UTC)
+
+	s.SendDigests(context.Background(), time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC))
+
+	if len(sender.sent) != 2 {
+		t.Errorf("sent %d messages, want an attempt for every user", len(sender.sent))
+	}
+}
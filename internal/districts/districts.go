@@ -0,0 +1,132 @@
+// Package districts maps Berlin zip codes and address text to Bezirke and Ortsteile
+// using an embedded offline dataset.
+package districts
+
+import (
+	"apartmenthunter/internal/normalize"
+	_ "embed"
+	"encoding/csv"
+	"sort"
+	"strings"
+)
+
+//go:embed berlin_plz.csv
+var berlinPLZ string
+
+// Area is an Ortsteil within its Bezirk, Ortsteil is empty if only the Bezirk is known
+type Area struct {
+	Ortsteil string
+	Bezirk   string
+}
+
+var (
+	byZip  = map[string][]Area{}
+	byName = map[string]Area{} // normalized Ortsteil or Bezirk name
+	names  []string            // normalized names, longest first
+)
+
+func init() {
+	records, err := csv.NewReader(strings.NewReader(berlinPLZ)).ReadAll()
+	if err != nil {
+		panic("districts: invalid embedded dataset: " + err.Error())
+	}
+
+	for _, record := range records[1:] {
+		area := Area{Ortsteil: record[1], Bezirk: record[2]}
+		byZip[record[0]] = append(byZip[record[0]], area)
+		byName[normalize.Text(area.Ortsteil)] = area
+		if _, ok := byName[normalize.Text(area.Bezirk)]; !ok {
+			byName[normalize.Text(area.Bezirk)] = Area{Bezirk: area.Bezirk}
+		}
+	}
+
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+}
+
+// Name returns the most specific name of the area
+func (a Area) Name() string {
+	if a.Ortsteil != "" {
+		return a.Ortsteil
+	}
+	return a.Bezirk
+}
+
+// In reports whether the area is, or lies within, the named Ortsteil or Bezirk
+func (a Area) In(name string) bool {
+	n := normalize.Text(name)
+	if n == "" {
+		return false
+	}
+	return (a.Ortsteil != "" && normalize.Text(a.Ortsteil) == n) || normalize.Text(a.Bezirk) == n
+}
+
+// ForZip returns the areas covered by a zip code, the primary area first
+func ForZip(zip string) []Area {
+	return byZip[strings.TrimSpace(zip)]
+}
+
+// Resolve looks up an area by its Ortsteil or Bezirk name. Names used for both
+// (e.g. "Neukölln") resolve to the Ortsteil.
+func Resolve(name string) (Area, bool) {
+	area, ok := byName[normalize.Text(name)]
+	return area, ok
+}
+
+// FromText finds the area named in free text such as an address. The last Ortsteil
+// mentioned wins, so "Marzahn-Hellersdorf - Marzahn" resolves to Marzahn; if only a
+// Bezirk is mentioned the area has no Ortsteil.
+func FromText(text string) (Area, bool) {
+	normalized := normalize.Text(text)
+
+	var found Area
+	foundAt, ok := -1, false
+	for _, name := range names {
+		for {
+			i := indexWord(normalized, name)
+			if i < 0 {
+				break
+			}
+			// blank the match so shorter names inside it ("Kreuzberg" in
+			// "Friedrichshain-Kreuzberg") are not found again
+			normalized = normalized[:i] + strings.Repeat(" ", len(name)) + normalized[i+len(name):]
+
+			area := byName[name]
+			switch {
+			case !ok,
+				area.Ortsteil != "" && found.Ortsteil == "",
+				area.Ortsteil != "" && i > foundAt:
+				found, foundAt, ok = area, i, true
+			}
+		}
+	}
+	return found, ok
+}
+
+// indexWord returns the position of name in text where it is not part of a longer
+// word, so "Neuköllner Straße" does not name Neukölln
+func indexWord(text, name string) int {
+	offset := 0
+	for {
+		i := strings.Index(text[offset:], name)
+		if i < 0 {
+			return -1
+		}
+		start, end := offset+i, offset+i+len(name)
+		if (start == 0 || !isLetter(text[start-1])) && (end == len(text) || !isLetter(text[end])) {
+			return start
+		}
+		offset = start + 1
+	}
+}
+
+func isLetter(b byte) bool {
+	return b >= 'a' && b <= 'z'
+}
@@ -0,0 +1,101 @@
+package districts
+
+import "testing"
+
+// TestForZip tests the zip code lookup
+func TestForZip(t *testing.T) {
+	tests := []struct {
+		zip      string
+		expected []Area
+	}{
+		{"12043", []Area{{"Neukölln", "Neukölln"}}},
+		{"10247", []Area{{"Friedrichshain", "Friedrichshain-Kreuzberg"}}},
+		{"10119", []Area{{"Mitte", "Mitte"}, {"Prenzlauer Berg", "Pankow"}}},
+		{" 10963 ", []Area{{"Kreuzberg", "Friedrichshain-Kreuzberg"}}},
+		{"99999", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zip, func(t *testing.T) {
+			result := ForZip(tt.zip)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("ForZip(%q) = %v, want %v", tt.zip, result, tt.expected)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("ForZip(%q)[%d] = %v, want %v", tt.zip, i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+// TestFromText tests finding areas in address text
+func TestFromText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected Area
+		found    bool
+	}{
+		{"ortsteil in address", "Oranienstraße 1, Kreuzberg, Berlin", Area{"Kreuzberg", "Friedrichshain-Kreuzberg"}, true},
+		{"without umlaut", "Neukoelln", Area{"Neukölln", "Neukölln"}, true},
+		{"bezirk only", "Friedrichshain-Kreuzberg", Area{Bezirk: "Friedrichshain-Kreuzberg"}, true},
+		{"bezirk and ortsteil", "Marzahn-Hellersdorf - Marzahn", Area{"Marzahn", "Marzahn-Hellersdorf"}, true},
+		{"ortsteil preferred over earlier bezirk", "Treptow-Köpenick, Adlershof", Area{"Adlershof", "Treptow-Köpenick"}, true},
+		{"longer name wins", "Neu-Hohenschönhausen", Area{"Neu-Hohenschönhausen", "Lichtenberg"}, true},
+		{"multi word ortsteil", "Märkisches Viertel", Area{"Märkisches Viertel", "Reinickendorf"}, true},
+		{"street named after district", "Neuköllner Straße 5, Berlin", Area{}, false},
+		{"no district", "Musterstraße 1, Berlin", Area{}, false},
+		{"empty", "", Area{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, found := FromText(tt.text)
+			if found != tt.found || result != tt.expected {
+				t.Errorf("FromText(%q) = %v, %v, want %v, %v", tt.text, result, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+// TestArea_In tests matching areas against Ortsteil and Bezirk names
+func TestArea_In(t *testing.T) {
+	kreuzberg := Area{"Kreuzberg", "Friedrichshain-Kreuzberg"}
+	tests := []struct {
+		name     string
+		area     Area
+		district string
+		expected bool
+	}{
+		{"same ortsteil", kreuzberg, "Kreuzberg", true},
+		{"parent bezirk", kreuzberg, "friedrichshain-kreuzberg", true},
+		{"sibling ortsteil", kreuzberg, "Friedrichshain", false},
+		{"umlaut spelled out", Area{"Köpenick", "Treptow-Köpenick"}, "Koepenick", true},
+		{"bezirk only area", Area{Bezirk: "Pankow"}, "Pankow", true},
+		{"empty name", kreuzberg, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.area.In(tt.district); result != tt.expected {
+				t.Errorf("%v.In(%q) = %v, want %v", tt.area, tt.district, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestResolve tests looking up areas by name
+func TestResolve(t *testing.T) {
+	if area, ok := Resolve("Neukölln"); !ok || area != (Area{"Neukölln", "Neukölln"}) {
+		t.Errorf("Resolve(Neukölln) = %v, %v", area, ok)
+	}
+	if area, ok := Resolve("treptow-koepenick"); !ok || area != (Area{Bezirk: "Treptow-Köpenick"}) {
+		t.Errorf("Resolve(treptow-koepenick) = %v, %v", area, ok)
+	}
+	if _, ok := Resolve("Potsdam"); ok {
+		t.Error("Resolve(Potsdam) should not be found")
+	}
+}
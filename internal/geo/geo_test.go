@@ -0,0 +1,146 @@
+package geo
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func loadTestGeocoder(t *testing.T) *Geocoder {
+	t.Helper()
+	g, err := LoadGeocoder("testdata/addresses.csv")
+	if err != nil {
+		t.Fatalf("LoadGeocoder() error = %v", err)
+	}
+	return g
+}
+
+// TestDistanceKm tests the haversine distance
+func TestDistanceKm(t *testing.T) {
+	alexanderplatz := Point{Lat: 52.5219, Lon: 13.4132}
+	hermannplatz := Point{Lat: 52.4869, Lon: 13.4245}
+
+	if d := DistanceKm(alexanderplatz, alexanderplatz); d != 0 {
+		t.Errorf("DistanceKm() to itself = %f, want 0", d)
+	}
+	if d := DistanceKm(alexanderplatz, hermannplatz); math.Abs(d-3.96) > 0.05 {
+		t.Errorf("DistanceKm() = %.2f km, want about 3.96 km", d)
+	}
+	if DistanceKm(alexanderplatz, hermannplatz) != DistanceKm(hermannplatz, alexanderplatz) {
+		t.Error("DistanceKm() should be symmetric")
+	}
+}
+
+// TestGeocoder_Geocode tests resolving address text to coordinates
+func TestGeocoder_Geocode(t *testing.T) {
+	g := loadTestGeocoder(t)
+
+	tests := []struct {
+		name     string
+		address  string
+		expected Point
+		found    bool
+	}{
+		{"exact address", "Weserstraße 5, 12047 Berlin", Point{52.4870, 13.4330}, true},
+		{"abbreviated street", "Weserstr. 5, Berlin", Point{52.4870, 13.4330}, true},
+		{"house number with letter", "Weserstraße 5 A, 12047 Berlin", Point{52.4869, 13.4332}, true},
+		{"number range uses first number", "Weserstraße 1-3, 12047 Berlin", Point{52.4880, 13.4310}, true},
+		{"unknown number falls back to street centre", "Weserstraße 99, 12047 Berlin", Point{52.4873, 13.4324}, true},
+		{"zip code picks the street", "Hauptstraße 10, 13158 Berlin", Point{52.5870, 13.3970}, true},
+		{"umlauts spelled out", "Strasse des 17. Juni 135", Point{52.5126, 13.3260}, true},
+		{"unknown street", "Musterweg 1, 12345 Berlin", Point{}, false},
+		{"empty", "", Point{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, found := g.Geocode(tt.address)
+			if found != tt.found {
+				t.Fatalf("Geocode(%q) found = %v, want %v", tt.address, found, tt.found)
+			}
+			if math.Abs(result.Lat-tt.expected.Lat) > 0.0001 || math.Abs(result.Lon-tt.expected.Lon) > 0.0001 {
+				t.Errorf("Geocode(%q) = %v, want %v", tt.address, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNewGeocoder_Invalid tests dataset validation
+func TestNewGeocoder_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"missing fields", "street,housenumber,zip,lat,lon\nWeserstraße,1,12047\n"},
+		{"invalid coordinates", "street,housenumber,zip,lat,lon\nWeserstraße,1,12047,north,13.4\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGeocoder(strings.NewReader(tt.data)); err == nil {
+				t.Error("NewGeocoder() should fail")
+			}
+		})
+	}
+	if _, err := LoadGeocoder("testdata/missing.csv"); err == nil {
+		t.Error("LoadGeocoder() should fail for a missing file")
+	}
+}
+
+// TestPolygon_Contains tests point in polygon checks for the supported GeoJSON types
+func TestPolygon_Contains(t *testing.T) {
+	square := `[[[13.40,52.48],[13.45,52.48],[13.45,52.50],[13.40,52.50],[13.40,52.48]]]`
+	withHole := `[[[13.40,52.48],[13.45,52.48],[13.45,52.50],[13.40,52.50],[13.40,52.48]],` +
+		`[[13.42,52.485],[13.43,52.485],[13.43,52.495],[13.42,52.495],[13.42,52.485]]]`
+	far := `[[[13.20,52.40],[13.25,52.40],[13.25,52.45],[13.20,52.40]]]`
+
+	inside := Point{Lat: 52.49, Lon: 13.41}
+	inHole := Point{Lat: 52.49, Lon: 13.425}
+	outside := Point{Lat: 52.52, Lon: 13.41}
+
+	tests := []struct {
+		name    string
+		geoJSON string
+		point   Point
+		want    bool
+	}{
+		{"inside polygon", `{"type":"Polygon","coordinates":` + square + `}`, inside, true},
+		{"outside polygon", `{"type":"Polygon","coordinates":` + square + `}`, outside, false},
+		{"inside hole", `{"type":"Polygon","coordinates":` + withHole + `}`, inHole, false},
+		{"outside hole", `{"type":"Polygon","coordinates":` + withHole + `}`, inside, true},
+		{"feature", `{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":` + square + `}}`, inside, true},
+		{"multipolygon", `{"type":"MultiPolygon","coordinates":[` + far + `,` + square + `]}`, inside, true},
+		{"feature collection", `{"type":"FeatureCollection","features":[` +
+			`{"type":"Feature","geometry":{"type":"Polygon","coordinates":` + far + `}},` +
+			`{"type":"Feature","geometry":{"type":"Polygon","coordinates":` + square + `}}]}`, inside, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polygon, err := ParsePolygon([]byte(tt.geoJSON))
+			if err != nil {
+				t.Fatalf("ParsePolygon() error = %v", err)
+			}
+			if result := polygon.Contains(tt.point); result != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.point, result, tt.want)
+			}
+		})
+	}
+}
+
+// TestParsePolygon_Invalid tests that unsupported GeoJSON is rejected
+func TestParsePolygon_Invalid(t *testing.T) {
+	tests := []string{
+		`not json`,
+		`{"type":"Point","coordinates":[13.4,52.5]}`,
+		`{"type":"FeatureCollection","features":[]}`,
+		`{"type":"Polygon","coordinates":"nope"}`,
+	}
+
+	for _, data := range tests {
+		if _, err := ParsePolygon([]byte(data)); err == nil {
+			t.Errorf("ParsePolygon(%s) should fail", data)
+		}
+	}
+}
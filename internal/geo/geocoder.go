@@ -0,0 +1,139 @@
+package geo
+
+import (
+	"apartmenthunter/internal/normalize"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	streetNumber = regexp.MustCompile(`^(.*?)\s*(\d+)\s*([a-z]?)(?:\s*[-–/]\s*\d+\s*[a-z]?)?$`)
+	zipCode      = regexp.MustCompile(`\b1[0-4]\d{3}\b`)
+)
+
+type address struct {
+	zip   string
+	point Point
+}
+
+// Geocoder resolves Berlin addresses to coordinates from an offline dataset
+type Geocoder struct {
+	houses  map[string][]address // "street number" -> addresses, one per zip code
+	streets map[string][]address // street -> all its addresses, for centroids
+}
+
+// LoadGeocoder reads a CSV dataset with the header street,housenumber,zip,lat,lon,
+// e.g. an export of the Berlin ALKIS address register.
+func LoadGeocoder(path string) (*Geocoder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening geocoding dataset: %w", err)
+	}
+	defer f.Close()
+	return NewGeocoder(f)
+}
+
+// NewGeocoder reads a geocoding dataset, see LoadGeocoder
+func NewGeocoder(r io.Reader) (*Geocoder, error) {
+	reader := csv.NewReader(r)
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("reading geocoding header: %w", err)
+	}
+
+	g := &Geocoder{
+		houses:  map[string][]address{},
+		streets: map[string][]address{},
+	}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading geocoding dataset: %w", err)
+		}
+		if len(record) < 5 {
+			return nil, fmt.Errorf("geocoding dataset line %d: expected 5 fields, got %d", line, len(record))
+		}
+
+		lat, errLat := strconv.ParseFloat(record[3], 64)
+		lon, errLon := strconv.ParseFloat(record[4], 64)
+		if errLat != nil || errLon != nil {
+			return nil, fmt.Errorf("geocoding dataset line %d: invalid coordinates", line)
+		}
+
+		street := normalize.Street(record[0])
+		entry := address{zip: strings.TrimSpace(record[2]), point: Point{Lat: lat, Lon: lon}}
+		houseKey := street + " " + strings.ToLower(strings.ReplaceAll(record[1], " ", ""))
+		g.houses[houseKey] = append(g.houses[houseKey], entry)
+		g.streets[street] = append(g.streets[street], entry)
+	}
+	return g, nil
+}
+
+// Size returns the number of addresses in the dataset
+func (g *Geocoder) Size() int {
+	total := 0
+	for _, entries := range g.houses {
+		total += len(entries)
+	}
+	return total
+}
+
+// Geocode resolves a free-text address like "Weserstraße 5, 12047 Berlin". Unknown
+// house numbers fall back to the centre of the street; a zip code in the text picks
+// between streets of the same name.
+func (g *Geocoder) Geocode(text string) (Point, bool) {
+	zip := zipCode.FindString(text)
+	streetPart := strings.TrimSpace(strings.Split(text, ",")[0])
+	streetPart = strings.TrimSpace(zipCode.ReplaceAllString(streetPart, ""))
+
+	normalized := normalize.Street(streetPart)
+	street, number := normalized, ""
+	if m := streetNumber.FindStringSubmatch(normalized); m != nil {
+		street, number = strings.TrimSpace(m[1]), m[2]+m[3]
+	}
+
+	if number != "" {
+		if entries := g.houses[street+" "+number]; len(entries) > 0 {
+			return pick(entries, zip)[0].point, true
+		}
+	}
+	if entries := g.streets[street]; len(entries) > 0 {
+		return centroid(pick(entries, zip)), true
+	}
+	return Point{}, false
+}
+
+// pick narrows entries to the zip code if any of them lies in it
+func pick(entries []address, zip string) []address {
+	if zip == "" {
+		return entries
+	}
+	var inZip []address
+	for _, e := range entries {
+		if e.zip == zip {
+			inZip = append(inZip, e)
+		}
+	}
+	if len(inZip) == 0 {
+		return entries
+	}
+	return inZip
+}
+
+func centroid(entries []address) Point {
+	var lat, lon float64
+	for _, e := range entries {
+		lat += e.point.Lat
+		lon += e.point.Lon
+	}
+	n := float64(len(entries))
+	return Point{Lat: lat / n, Lon: lon / n}
+}
@@ -0,0 +1,124 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Polygon is an area read from GeoJSON, made of one or more polygons with optional holes
+type Polygon struct {
+	parts [][][]Point // per polygon: the outer ring followed by its holes
+}
+
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Features    []geoJSON       `json:"features"`
+}
+
+// LoadPolygon reads a GeoJSON file, see ParsePolygon
+func LoadPolygon(path string) (*Polygon, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading polygon file: %w", err)
+	}
+	return ParsePolygon(data)
+}
+
+// ParsePolygon parses a GeoJSON Polygon or MultiPolygon, either bare or wrapped in a
+// Feature or FeatureCollection. All polygons of a collection form one area.
+func ParsePolygon(data []byte) (*Polygon, error) {
+	var doc geoJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing GeoJSON: %w", err)
+	}
+
+	polygon := &Polygon{}
+	if err := polygon.add(&doc); err != nil {
+		return nil, err
+	}
+	if len(polygon.parts) == 0 {
+		return nil, fmt.Errorf("GeoJSON contains no polygon")
+	}
+	return polygon, nil
+}
+
+func (p *Polygon) add(doc *geoJSON) error {
+	switch doc.Type {
+	case "FeatureCollection":
+		for i := range doc.Features {
+			if err := p.add(&doc.Features[i]); err != nil {
+				return err
+			}
+		}
+	case "Feature":
+		if doc.Geometry != nil {
+			return p.add(doc.Geometry)
+		}
+	case "Polygon":
+		var rings [][][2]float64
+		if err := json.Unmarshal(doc.Coordinates, &rings); err != nil {
+			return fmt.Errorf("parsing polygon coordinates: %w", err)
+		}
+		p.parts = append(p.parts, toRings(rings))
+	case "MultiPolygon":
+		var polygons [][][][2]float64
+		if err := json.Unmarshal(doc.Coordinates, &polygons); err != nil {
+			return fmt.Errorf("parsing multipolygon coordinates: %w", err)
+		}
+		for _, rings := range polygons {
+			p.parts = append(p.parts, toRings(rings))
+		}
+	default:
+		return fmt.Errorf("unsupported GeoJSON type %q", doc.Type)
+	}
+	return nil
+}
+
+// toRings converts GeoJSON [lon, lat] positions to points
+func toRings(rings [][][2]float64) [][]Point {
+	result := make([][]Point, 0, len(rings))
+	for _, ring := range rings {
+		points := make([]Point, len(ring))
+		for i, position := range ring {
+			points[i] = Point{Lat: position[1], Lon: position[0]}
+		}
+		result = append(result, points)
+	}
+	return result
+}
+
+// Contains reports whether the point lies inside the area and outside its holes
+func (p *Polygon) Contains(point Point) bool {
+	for _, rings := range p.parts {
+		if len(rings) == 0 || !inRing(point, rings[0]) {
+			continue
+		}
+		inHole := false
+		for _, hole := range rings[1:] {
+			if inRing(point, hole) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// inRing casts a ray along the latitude and counts the edges it crosses
+func inRing(point Point, ring []Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > point.Lat) != (b.Lat > point.Lat) &&
+			point.Lon < (b.Lon-a.Lon)*(point.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"apartmenthunter/internal/http"
+	"context"
+)
+
+// captured is the last response a scraper received
+type captured struct {
+	method     string
+	url        string
+	statusCode int
+	body       []byte
+}
+
+// Wrap returns a client keeping the last response of the scraper for snippets
+func (m *Monitor) Wrap(name string, client http.HTTPClient) http.HTTPClient {
+	return &capturingClient{client: client, name: name, monitor: m}
+}
+
+type capturingClient struct {
+	client  http.HTTPClient
+	name    string
+	monitor *Monitor
+}
+
+func (c *capturingClient) Get(ctx context.Context, url string, headers map[string]string) (*http.HTTPResponse, error) {
+	resp, err := c.client.Get(ctx, url, headers)
+	return c.capture("GET", url, resp, err)
+}
+
+func (c *capturingClient) Post(ctx context.Context, url string, formData map[string][]string, headers map[string]string) (*http.HTTPResponse, error) {
+	resp, err := c.client.Post(ctx, url, formData, headers)
+	return c.capture("POST", url, resp, err)
+}
+
+func (c *capturingClient) PostJSON(ctx context.Context, url string, jsonBody []byte, headers map[string]string) (*http.HTTPResponse, error) {
+	resp, err := c.client.PostJSON(ctx, url, jsonBody, headers)
+	return c.capture("POST", url, resp, err)
+}
+
+func (c *capturingClient) capture(method, url string, resp *http.HTTPResponse, err error) (*http.HTTPResponse, error) {
+	if err == nil && resp != nil {
+		c.monitor.mu.Lock()
+		c.monitor.scraper(c.name).last = &captured{method: method, url: url, statusCode: resp.StatusCode, body: resp.Body}
+		c.monitor.mu.Unlock()
+	}
+	return resp, err
+}
@@ -0,0 +1,208 @@
+// Package health notices scrapers that silently stopped working, e.g. because a company
+// changed its markup and the selectors no longer match, and alerts the admins.
+package health
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alerter delivers HTML alerts to the admins, alerts with the same key are the same
+// problem even if their details differ
+type Alerter interface {
+	Alert(ctx context.Context, key, htmlMessage string) error
+}
+
+// Problem is a kind of breakage
+type Problem string
+
+const (
+	NoListings    Problem = "no listings"
+	MissingFields Problem = "missing fields"
+	DecodeError   Problem = "undecodable response"
+)
+
+// Thresholds configure when a scraper is considered broken
+type Thresholds struct {
+	EmptyScrapes      int     // consecutive empty scrapes of a scraper that had listings before
+	MissingFieldRatio float64 // share of listings lacking a field
+	MinListings       int     // fewer listings are too few to judge missing fields
+	SnippetBytes      int     // how much of the response is saved
+}
+
+type scraperHealth struct {
+	hadListings  bool
+	emptyScrapes int
+	problems     map[Problem]bool // alerted and not yet recovered
+	last         *captured
+}
+
+// Monitor checks the results of every scrape and alerts once when a problem appears and
+// once when it is gone
+type Monitor struct {
+	alerter    Alerter
+	snippetDir string
+	thresholds Thresholds
+	now        func() time.Time
+
+	mu       sync.Mutex
+	scrapers map[string]*scraperHealth
+}
+
+// NewMonitor creates a monitor saving the responses of broken scrapers to snippetDir
+func NewMonitor(alerter Alerter, snippetDir string, thresholds Thresholds) *Monitor {
+	return &Monitor{
+		alerter:    alerter,
+		snippetDir: snippetDir,
+		thresholds: thresholds,
+		now:        time.Now,
+		scrapers:   make(map[string]*scraperHealth),
+	}
+}
+
+func (m *Monitor) scraper(name string) *scraperHealth {
+	s, ok := m.scrapers[name]
+	if !ok {
+		s = &scraperHealth{problems: make(map[Problem]bool)}
+		m.scrapers[name] = s
+	}
+	return s
+}
+
+// HadListings tells the monitor that a scraper found listings before it was started, so
+// empty results right after a restart count as well
+func (m *Monitor) HadListings(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scraper(name).hadListings = true
+}
+
+// Report checks the result of a scrape
+func (m *Monitor) Report(ctx context.Context, name string, listings []common.Listing, err error) {
+	if errors.Is(err, common.ErrUnchanged) || (err != nil && !isDecodeError(err)) {
+		return // nothing was parsed, failed requests say nothing about the parser
+	}
+
+	m.mu.Lock()
+	s := m.scraper(name)
+	found := make(map[Problem]string)
+	checked := map[Problem]bool{DecodeError: true}
+	switch {
+	case err != nil:
+		found[DecodeError] = err.Error()
+	case len(listings) == 0:
+		s.emptyScrapes++
+		checked[NoListings] = true
+		if s.hadListings && s.emptyScrapes >= m.thresholds.EmptyScrapes {
+			found[NoListings] = fmt.Sprintf("no listings in the last %d scrapes", s.emptyScrapes)
+		}
+	default:
+		s.hadListings = true
+		s.emptyScrapes = 0
+		checked[NoListings] = true
+		checked[MissingFields] = true
+		if missing := m.missingFields(listings); missing != "" {
+			found[MissingFields] = missing
+		}
+	}
+
+	type alert struct{ key, message string }
+	var alerts []alert
+	for _, problem := range []Problem{DecodeError, NoListings, MissingFields} {
+		if !checked[problem] {
+			continue
+		}
+		detail, broken := found[problem]
+		switch {
+		case broken && !s.problems[problem]:
+			s.problems[problem] = true
+			alerts = append(alerts, alert{name + " " + string(problem), m.brokenMessage(name, problem, detail, s.last)})
+		case !broken && s.problems[problem]:
+			delete(s.problems, problem)
+			alerts = append(alerts, alert{name + " " + string(problem) + " recovered",
+				fmt.Sprintf("✅ <b>%s</b> recovered: %s", html.EscapeString(name), problem)})
+		}
+	}
+	m.mu.Unlock()
+
+	for _, a := range alerts {
+		if err := m.alerter.Alert(ctx, a.key, a.message); err != nil {
+			log.Printf("[%s] error sending health alert: %v", name, err)
+		}
+	}
+}
+
+// missingFields describes the fields lacking in too many listings, empty if none
+func (m *Monitor) missingFields(listings []common.Listing) string {
+	if len(listings) < m.thresholds.MinListings {
+		return ""
+	}
+	fields := []struct {
+		name  string
+		empty func(common.Listing) bool
+	}{
+		{"price", func(l common.Listing) bool { return l.Price == "" }},
+		{"size", func(l common.Listing) bool { return l.Size == "" }},
+		// Degewo lists the district instead of the zip code
+		{"zip code", func(l common.Listing) bool { return l.ZipCode == "" && l.District == "" }},
+	}
+
+	var missing []string
+	for _, field := range fields {
+		empty := 0
+		for _, listing := range listings {
+			if field.empty(listing) {
+				empty++
+			}
+		}
+		if float64(empty) >= m.thresholds.MissingFieldRatio*float64(len(listings)) {
+			missing = append(missing, fmt.Sprintf("%d/%d without %s", empty, len(listings), field.name))
+		}
+	}
+	return strings.Join(missing, ", ")
+}
+
+func (m *Monitor) brokenMessage(name string, problem Problem, detail string, last *captured) string {
+	message := fmt.Sprintf("⚠️ <b>%s</b> may be broken: %s\n%s", html.EscapeString(name), problem, html.EscapeString(detail))
+	if last == nil {
+		return message
+	}
+	path, err := m.saveSnippet(name, last)
+	if err != nil {
+		log.Printf("[%s] error saving response snippet: %v", name, err)
+		return message
+	}
+	return message + fmt.Sprintf("\nResponse of %s saved to <code>%s</code>", html.EscapeString(last.url), html.EscapeString(path))
+}
+
+// saveSnippet writes the start of the last response of a scraper to the snippet dir
+func (m *Monitor) saveSnippet(name string, last *captured) (string, error) {
+	if err := os.MkdirAll(m.snippetDir, 0o755); err != nil {
+		return "", err
+	}
+	body := last.body
+	if m.thresholds.SnippetBytes > 0 && len(body) > m.thresholds.SnippetBytes {
+		body = body[:m.thresholds.SnippetBytes]
+	}
+	path := filepath.Join(m.snippetDir, fmt.Sprintf("%s-%s.txt", name, m.now().Format("20060102-150405")))
+	content := fmt.Sprintf("%s %s\nStatus: %d\n\n%s", last.method, last.url, last.statusCode, body)
+	return path, os.WriteFile(path, []byte(content), 0o644)
+}
+
+// isDecodeError reports whether a scrape failed because the response was not the JSON
+// the scraper expects
+func isDecodeError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
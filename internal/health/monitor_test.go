@@ -0,0 +1,156 @@
+package health
+
+import (
+	"apartmenthunter/internal/http"
+	"apartmenthunter/internal/http/mock"
+	"apartmenthunter/internal/scraping/common"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type stubAlerter struct {
+	keys   []string
+	alerts []string
+}
+
+func (s *stubAlerter) Alert(ctx context.Context, key, htmlMessage string) error {
+	s.keys = append(s.keys, key)
+	s.alerts = append(s.alerts, htmlMessage)
+	return nil
+}
+
+var thresholds = Thresholds{EmptyScrapes: 3, MissingFieldRatio: 0.5, MinListings: 2, SnippetBytes: 10}
+
+func complete(n int) []common.Listing {
+	listings := make([]common.Listing, n)
+	for i := range listings {
+		listings[i] = common.Listing{ID: fmt.Sprint(i), Price: "700", Size: "50", ZipCode: "12045"}
+	}
+	return listings
+}
+
+// TestMonitor_Report tests which sequences of scrape results raise and clear alerts
+func TestMonitor_Report(t *testing.T) {
+	decodeErr := fmt.Errorf("error parsing json response: %w", json.Unmarshal([]byte("<html>"), &struct{}{}))
+	noZip := complete(4)
+	for i := range noZip[:2] {
+		noZip[i].ZipCode = ""
+	}
+
+	type scrape struct {
+		listings []common.Listing
+		err      error
+	}
+	tests := []struct {
+		name        string
+		hadListings bool
+		scrapes     []scrape
+		want        []string // alert prefixes in order
+	}{
+		{
+			name:    "empty after listings",
+			scrapes: []scrape{{listings: complete(3)}, {}, {}, {}, {}},
+			want:    []string{"⚠️ <b>Gewobag</b> may be broken: no listings"},
+		},
+		{
+			name:    "never had listings",
+			scrapes: []scrape{{}, {}, {}, {}},
+		},
+		{
+			name:        "had listings before restart",
+			hadListings: true,
+			scrapes:     []scrape{{}, {}, {}},
+			want:        []string{"⚠️ <b>Gewobag</b> may be broken: no listings"},
+		},
+		{
+			name:    "recovers",
+			scrapes: []scrape{{listings: complete(3)}, {}, {}, {}, {listings: complete(3)}},
+			want:    []string{"⚠️ <b>Gewobag</b> may be broken: no listings", "✅ <b>Gewobag</b> recovered: no listings"},
+		},
+		{
+			name:    "failed requests are ignored",
+			scrapes: []scrape{{listings: complete(3)}, {}, {err: errors.New("timeout")}, {}, {err: common.ErrUnchanged}},
+		},
+		{
+			name:    "missing fields",
+			scrapes: []scrape{{listings: noZip}, {listings: noZip}},
+			want:    []string{"⚠️ <b>Gewobag</b> may be broken: missing fields\n2/4 without zip code"},
+		},
+		{
+			name:    "too few listings to judge fields",
+			scrapes: []scrape{{listings: []common.Listing{{ID: "1"}}}},
+		},
+		{
+			name:    "decode error",
+			scrapes: []scrape{{err: decodeErr}, {err: decodeErr}, {listings: complete(1)}},
+			want:    []string{"⚠️ <b>Gewobag</b> may be broken: undecodable response", "✅ <b>Gewobag</b> recovered: undecodable response"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerter := &stubAlerter{}
+			m := NewMonitor(alerter, t.TempDir(), thresholds)
+			if tt.hadListings {
+				m.HadListings("Gewobag")
+			}
+			for _, s := range tt.scrapes {
+				m.Report(context.Background(), "Gewobag", s.listings, s.err)
+			}
+
+			if len(alerter.alerts) != len(tt.want) {
+				t.Fatalf("alerts = %q, want %d", alerter.alerts, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(alerter.alerts[i], want) {
+					t.Errorf("alert %d = %q, want prefix %q", i, alerter.alerts[i], want)
+				}
+			}
+		})
+	}
+}
+
+// TestMonitor_Snippet tests that the response of a broken scraper is saved
+func TestMonitor_Snippet(t *testing.T) {
+	alerter := &stubAlerter{}
+	dir := t.TempDir()
+	m := NewMonitor(alerter, dir, thresholds)
+	m.HadListings("WBM")
+
+	client := mock.NewHTTPClient()
+	client.GetFunc = func(ctx context.Context, url string, headers map[string]string) (*http.HTTPResponse, error) {
+		return &http.HTTPResponse{StatusCode: 200, Body: []byte("<html><body>new markup</body></html>")}, nil
+	}
+	wrapped := m.Wrap("WBM", client)
+	for i := 0; i < thresholds.EmptyScrapes; i++ {
+		if _, err := wrapped.Get(context.Background(), "https://www.wbm.de/angebote/", nil); err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		m.Report(context.Background(), "WBM", nil, nil)
+	}
+
+	if len(alerter.alerts) != 1 || !strings.Contains(alerter.alerts[0], "https://www.wbm.de/angebote/") {
+		t.Fatalf("alerts = %q, want one naming the URL", alerter.alerts)
+	}
+	if alerter.keys[0] != "WBM no listings" {
+		t.Errorf("alert key = %q, want the scraper and problem", alerter.keys[0])
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("snippet dir = %v, %v, want one file", files, err)
+	}
+	if !strings.Contains(alerter.alerts[0], files[0].Name()) {
+		t.Errorf("alert %q does not name snippet %s", alerter.alerts[0], files[0].Name())
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	want := "GET https://www.wbm.de/angebote/\nStatus: 200\n\n<html><bod"
+	if string(content) != want {
+		t.Errorf("snippet = %q, want %q", content, want)
+	}
+}
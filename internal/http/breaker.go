@@ -0,0 +1,175 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending a request while a host's circuit is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // requests pass
+	CircuitOpen                         // requests fail fast until the cooldown is over
+	CircuitHalfOpen                     // a single trial request decides whether to close again
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuits holds a circuit breaker per host
+type circuits struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	listener  func(host string, state CircuitState) // optional
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+type circuit struct {
+	state     CircuitState
+	failures  int // consecutive
+	openUntil time.Time
+	trial     bool // a half-open trial request is in flight
+}
+
+// WithCircuitBreaker stops sending requests to a host for cooldown after threshold
+// consecutive failed requests
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.circuits = &circuits{threshold: threshold, cooldown: cooldown, now: time.Now, hosts: map[string]*circuit{}}
+	}
+}
+
+// WithCircuitListener calls listener whenever the circuit of a host changes state, after
+// WithCircuitBreaker. It must not block for long, requests wait for it.
+func WithCircuitListener(listener func(host string, state CircuitState)) Option {
+	return func(c *Client) {
+		if c.circuits != nil {
+			c.circuits.listener = listener
+		}
+	}
+}
+
+// Circuits returns the circuit state of every host requested so far
+func (c *Client) Circuits() map[string]CircuitState {
+	states := map[string]CircuitState{}
+	if c.circuits == nil {
+		return states
+	}
+	c.circuits.mu.Lock()
+	defer c.circuits.mu.Unlock()
+	for host, hc := range c.circuits.hosts {
+		states[host] = hc.state
+	}
+	return states
+}
+
+// allow returns ErrCircuitOpen if no request may be sent to the host right now
+func (cs *circuits) allow(host string) error {
+	if cs == nil {
+		return nil
+	}
+	var changed bool
+	defer func() {
+		if changed {
+			cs.notify(host, CircuitHalfOpen)
+		}
+	}()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	hc := cs.get(host)
+	switch hc.state {
+	case CircuitOpen:
+		if remaining := hc.openUntil.Sub(cs.now()); remaining > 0 {
+			return fmt.Errorf("%s: %w for another %s", host, ErrCircuitOpen, remaining.Round(time.Second))
+		}
+		hc.state, changed = CircuitHalfOpen, true
+		log.Printf("[http] circuit for %s half-open, sending a trial request", host)
+	case CircuitHalfOpen:
+		if hc.trial {
+			return fmt.Errorf("%s: %w, trial request pending", host, ErrCircuitOpen)
+		}
+	}
+	hc.trial = hc.state == CircuitHalfOpen
+	return nil
+}
+
+// record updates the host's circuit with the outcome of a request
+func (cs *circuits) record(host string, failed bool) {
+	if cs == nil {
+		return
+	}
+	var changed bool
+	var state CircuitState
+	defer func() {
+		if changed {
+			cs.notify(host, state)
+		}
+	}()
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	hc := cs.get(host)
+	hc.trial = false
+	if !failed {
+		if hc.state != CircuitClosed {
+			log.Printf("[http] circuit for %s closed", host)
+			changed, state = true, CircuitClosed
+		}
+		hc.state, hc.failures = CircuitClosed, 0
+		return
+	}
+
+	hc.failures++
+	if hc.state == CircuitHalfOpen || hc.failures >= cs.threshold {
+		changed, state = hc.state != CircuitOpen, CircuitOpen
+		hc.state = CircuitOpen
+		hc.openUntil = cs.now().Add(cs.cooldown)
+		log.Printf("[http] circuit for %s open for %s after %d consecutive failures", host, cs.cooldown, hc.failures)
+	}
+}
+
+// release ends a trial request without an outcome
+func (cs *circuits) release(host string) {
+	if cs == nil {
+		return
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.get(host).trial = false
+}
+
+// notify tells the listener about a state change, called without holding the lock
+func (cs *circuits) notify(host string, state CircuitState) {
+	if cs.listener != nil {
+		cs.listener(host, state)
+	}
+}
+
+func (cs *circuits) get(host string) *circuit {
+	hc, ok := cs.hosts[host]
+	if !ok {
+		hc = &circuit{}
+		cs.hosts[host] = hc
+	}
+	return hc
+}
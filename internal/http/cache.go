@@ -0,0 +1,90 @@
+package http
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"sync"
+)
+
+// responseCache remembers the validators and the last response per URL for conditional
+// GET requests
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	etag         string
+	lastModified string
+	hash         [sha256.Size]byte
+	resp         *HTTPResponse
+}
+
+// WithConditionalRequests sends If-None-Match and If-Modified-Since on GET requests to
+// URLs fetched before. A 304 is answered with the cached response, and responses whose
+// body did not change are marked NotModified so that scrapers can skip parsing.
+func WithConditionalRequests() Option {
+	return func(c *Client) {
+		c.cache = &responseCache{entries: map[string]*cacheEntry{}}
+	}
+}
+
+// conditionalHeaders returns the headers extended by the validators stored for the URL
+func (rc *responseCache) conditionalHeaders(url string, headers map[string]string) map[string]string {
+	if rc == nil {
+		return headers
+	}
+	rc.mu.Lock()
+	entry, ok := rc.entries[url]
+	rc.mu.Unlock()
+	if !ok || (entry.etag == "" && entry.lastModified == "") {
+		return headers
+	}
+
+	extended := make(map[string]string, len(headers)+2)
+	for key, value := range headers {
+		extended[key] = value
+	}
+	if entry.etag != "" {
+		extended["If-None-Match"] = entry.etag
+	}
+	if entry.lastModified != "" {
+		extended["If-Modified-Since"] = entry.lastModified
+	}
+	return extended
+}
+
+// update stores a successful response and returns the response to hand to the caller
+func (rc *responseCache) update(url string, resp *HTTPResponse) *HTTPResponse {
+	if rc == nil || resp == nil {
+		return resp
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[url]
+
+	if resp.StatusCode == http.StatusNotModified {
+		if !ok {
+			return resp
+		}
+		cached := *entry.resp
+		cached.NotModified = true
+		return &cached
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp
+	}
+
+	hash := sha256.Sum256(resp.Body)
+	resp.NotModified = ok && entry.hash == hash
+	headers := http.Header(resp.Headers)
+	stored := *resp
+	stored.NotModified = false
+	rc.entries[url] = &cacheEntry{
+		etag:         headers.Get("ETag"),
+		lastModified: headers.Get("Last-Modified"),
+		hash:         hash,
+		resp:         &stored,
+	}
+	return resp
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestClient_ConditionalRequests tests validators, 304 handling and unchanged bodies
+func TestClient_ConditionalRequests(t *testing.T) {
+	body := "v1"
+	etag := `"1"`
+	var conditions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditions = append(conditions, r.Header.Get("If-None-Match")+"|"+r.Header.Get("If-Modified-Since"))
+		if etag != "" && r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		} else {
+			w.Header().Set("Last-Modified", "Mon, 19 Oct 2026 10:00:00 GMT")
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	c := NewClient(time.Second, WithConditionalRequests())
+	ctx := context.Background()
+	get := func() *HTTPResponse {
+		t.Helper()
+		resp, err := c.Get(ctx, server.URL, map[string]string{"User-Agent": "test"})
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		return resp
+	}
+
+	steps := []struct {
+		name            string
+		prepare         func()
+		wantBody        string
+		wantNotModified bool
+	}{
+		{"first fetch", func() {}, "v1", false},
+		{"304 returns the cached body", func() {}, "v1", true},
+		{"changed page", func() { body, etag = "v2", `"2"` }, "v2", false},
+		{"same body without validators", func() { etag = "" }, "v2", true},
+		{"changed body without validators", func() { body = "v3" }, "v3", false},
+	}
+
+	for _, step := range steps {
+		step.prepare()
+		resp := get()
+		if resp.StatusCode != 200 || string(resp.Body) != step.wantBody || resp.NotModified != step.wantNotModified {
+			t.Errorf("%s: Get() = %d %q NotModified=%v, want 200 %q NotModified=%v",
+				step.name, resp.StatusCode, resp.Body, resp.NotModified, step.wantBody, step.wantNotModified)
+		}
+	}
+
+	want := []string{"|", `"1"|`, `"1"|`, `"2"|`, "|Mon, 19 Oct 2026 10:00:00 GMT"}
+	for i := range want {
+		if conditions[i] != want[i] {
+			t.Errorf("conditional headers of request %d = %q, want %q", i, conditions[i], want[i])
+		}
+	}
+}
+
+// TestClient_WithoutConditionalRequests tests that responses are not compared by default
+func TestClient_WithoutConditionalRequests(t *testing.T) {
+	server, _ := statusServer(t, http.StatusOK)
+	c := NewClient(time.Second)
+
+	_, _ = c.Get(context.Background(), server.URL, nil)
+	resp, _ := c.Get(context.Background(), server.URL, nil)
+	if resp.NotModified {
+		t.Error("NotModified should only be set with WithConditionalRequests")
+	}
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -21,30 +22,41 @@ type HTTPResponse struct {
 	StatusCode int
 	Body       []byte
 	Headers    map[string][]string
+	// NotModified is set if the body is the same as when the URL was fetched before,
+	// only with WithConditionalRequests
+	NotModified bool
 }
 
 type Client struct {
 	httpClient *http.Client
+	retry      RetryPolicy
+	circuits   *circuits // nil if the circuit breaker is disabled
+	limiter    *limiter  // nil if requests are not rate limited
+	cache      *responseCache
+	proxy      *proxyAssignment // nil to connect directly
+	sleep      func(ctx context.Context, d time.Duration) error
 }
 
-func NewClient(timeout time.Duration) *Client {
-	return &Client{
+// Option configures optional behaviour of the client
+type Option func(*Client)
+
+// NewClient creates a client making a single attempt per request unless configured otherwise
+func NewClient(timeout time.Duration, opts ...Option) *Client {
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
+		retry: RetryPolicy{MaxAttempts: 1},
+		sleep: sleepContext,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 func (c *Client) Get(ctx context.Context, reqURL string, headers map[string]string) (*HTTPResponse, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GET request w context: %w", err)
-	}
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
-	return c.doRequest(req)
+	return c.doRequest(ctx, http.MethodGet, reqURL, nil, headers)
 }
 
 func (c *Client) Post(ctx context.Context, reqURL string, formData map[string][]string, headers map[string]string) (*HTTPResponse, error) {
@@ -55,44 +67,128 @@ func (c *Client) Post(ctx context.Context, reqURL string, formData map[string][]
 		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, strings.NewReader(form.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("error creating post request: %w", err)
+	return c.doRequest(ctx, http.MethodPost, reqURL, []byte(form.Encode()), headers)
+}
+
+func (c *Client) PostJSON(ctx context.Context, reqURL string, jsonBody []byte, headers map[string]string) (*HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, reqURL, jsonBody, headers)
+}
+
+// doRequest sends the request through the circuit breaker of its host, retrying it
+// according to the retry policy
+func (c *Client) doRequest(ctx context.Context, method, reqURL string, body []byte, headers map[string]string) (*HTTPResponse, error) {
+	host := hostOf(reqURL)
+	if err := c.circuits.allow(host); err != nil {
+		return nil, err
 	}
 
-	for key, value := range headers {
-		req.Header.Set(key, value)
+	attempts := 1
+	if method == http.MethodGet || c.retry.RetryPOST {
+		attempts = max(c.retry.MaxAttempts, 1)
+	}
+	if method == http.MethodGet {
+		headers = c.cache.conditionalHeaders(reqURL, headers)
 	}
-	return c.doRequest(req)
+
+	var resp *HTTPResponse
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = c.limiter.wait(ctx, host, c.sleep); err != nil {
+			break
+		}
+		resp, err = c.attempt(ctx, method, reqURL, body, headers)
+		if errors.Is(err, ErrNoProxy) {
+			break
+		}
+		requested := retryAfter(resp, time.Now())
+		c.limiter.pause(host, requested)
+		if attempt == attempts || !retryable(resp, err) || ctx.Err() != nil {
+			break
+		}
+		if c.retry.MaxDelay > 0 && requested > c.retry.MaxDelay {
+			break // the host asked for a longer break than a retry should take
+		}
+		if sleepErr := c.sleep(ctx, max(c.retry.backoff(attempt), requested)); sleepErr != nil {
+			break
+		}
+	}
+
+	if ctx.Err() != nil || errors.Is(err, ErrNoProxy) {
+		c.circuits.release(host) // the request was not sent, says nothing about the host
+	} else {
+		c.circuits.record(host, retryable(resp, err))
+	}
+	if err == nil && method == http.MethodGet {
+		resp = c.cache.update(reqURL, resp)
+	}
+	return resp, err
 }
 
-func (c *Client) PostJSON(ctx context.Context, reqURL string, jsonBody []byte, headers map[string]string) (*HTTPResponse, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewBuffer(jsonBody))
+func (c *Client) attempt(ctx context.Context, method, reqURL string, body []byte, headers map[string]string) (*HTTPResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reader)
 	if err != nil {
-		return nil, fmt.Errorf("error creating JSON POST request: %w", err)
+		return nil, fmt.Errorf("error creating %s request: %w", method, err)
 	}
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
 
-	return c.doRequest(req)
-}
-
-func (c *Client) doRequest(req *http.Request) (*HTTPResponse, error) {
-	resp, err := c.httpClient.Do(req)
+	httpClient := c.httpClient
+	proxyIndex := -1
+	if c.proxy != nil {
+		if proxyIndex, err = c.proxy.current(); err != nil {
+			return nil, err
+		}
+		httpClient = c.proxy.httpClient(httpClient, proxyIndex)
+	}
+	resp, err := httpClient.Do(req)
+	if c.proxy != nil && ctx.Err() == nil {
+		c.proxy.record(proxyIndex, resp, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("error reading response body: %w", err)
 	}
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" && len(respBody) > 0 {
+		respBody, err = decodeBody(encoding, respBody)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding %s response body: %w", encoding, err)
+		}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+	}
 
 	return &HTTPResponse{
 		StatusCode: resp.StatusCode,
-		Body:       body,
+		Body:       respBody,
 		Headers:    resp.Header,
 	}, nil
 }
+
+func hostOf(reqURL string) string {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return reqURL
+	}
+	return strings.ToLower(u.Host)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// statusServer answers with the given status codes in turn, repeating the last one
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		w.WriteHeader(statuses[min(n, len(statuses)-1)])
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func noSleep(context.Context, time.Duration) error { return nil }
+
+// TestClient_Retry tests which requests are repeated
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     RetryPolicy
+		post       bool
+		statuses   []int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{"single attempt by default", RetryPolicy{}, false, []int{503, 200}, 503, 1},
+		{"get retried until success", RetryPolicy{MaxAttempts: 3}, false, []int{503, 502, 200}, 200, 3},
+		{"get gives up after max attempts", RetryPolicy{MaxAttempts: 2}, false, []int{500}, 500, 2},
+		{"client errors are not retried", RetryPolicy{MaxAttempts: 3}, false, []int{404, 200}, 404, 1},
+		{"rate limiting is retried", RetryPolicy{MaxAttempts: 3}, false, []int{429, 200}, 200, 2},
+		{"post not retried by default", RetryPolicy{MaxAttempts: 3}, true, []int{503, 200}, 503, 1},
+		{"post retried if configured", RetryPolicy{MaxAttempts: 3, RetryPOST: true}, true, []int{503, 200}, 200, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := statusServer(t, tt.statuses...)
+			c := NewClient(time.Second, WithRetry(tt.policy))
+			c.sleep = noSleep
+
+			var resp *HTTPResponse
+			var err error
+			if tt.post {
+				resp, err = c.Post(context.Background(), server.URL, map[string][]string{"a": {"1"}}, nil)
+			} else {
+				resp, err = c.Get(context.Background(), server.URL, nil)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus || calls.Load() != tt.wantCalls {
+				t.Errorf("status %d after %d calls, want %d after %d", resp.StatusCode, calls.Load(), tt.wantStatus, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// TestClient_RetryResendsBody tests that every attempt of a POST carries the body
+func TestClient_RetryResendsBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		bodies = append(bodies, r.Form.Get("limit"))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(time.Second, WithRetry(RetryPolicy{MaxAttempts: 2, RetryPOST: true}))
+	c.sleep = noSleep
+	headers := map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+	if _, err := c.Post(context.Background(), server.URL, map[string][]string{"limit": {"50"}}, headers); err != nil {
+		t.Fatalf("Post() unexpected error: %v", err)
+	}
+	if len(bodies) != 2 || bodies[0] != "50" || bodies[1] != "50" {
+		t.Errorf("form values per attempt = %v, want the body on both attempts", bodies)
+	}
+}
+
+// TestRetryPolicy_Backoff tests exponential delays with jitter
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{4, 2500 * time.Millisecond, 5 * time.Second},
+		{40, 2500 * time.Millisecond, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			if d := p.backoff(tt.attempt); d < tt.min || d > tt.max {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+// TestClient_CircuitBreaker tests opening, half-opening and closing a host's circuit
+func TestClient_CircuitBreaker(t *testing.T) {
+	server, calls := statusServer(t, 500, 500, 500, 500, 200)
+	var transitions []string
+	c := NewClient(time.Second, WithCircuitBreaker(2, time.Minute), WithCircuitListener(func(host string, state CircuitState) {
+		transitions = append(transitions, state.String())
+	}))
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	c.circuits.now = func() time.Time { return now }
+	ctx := context.Background()
+	host := hostOf(server.URL)
+
+	_, _ = c.Get(ctx, server.URL, nil)
+	if state := c.Circuits()[host]; state != CircuitClosed {
+		t.Errorf("state after one failure = %v, want closed", state)
+	}
+	_, _ = c.Get(ctx, server.URL, nil)
+	if state := c.Circuits()[host]; state != CircuitOpen {
+		t.Errorf("state after two failures = %v, want open", state)
+	}
+
+	if _, err := c.Get(ctx, server.URL, nil); !errors.Is(err, ErrCircuitOpen) || calls.Load() != 2 {
+		t.Errorf("Get() while open = %v after %d calls, want ErrCircuitOpen without a request", err, calls.Load())
+	}
+
+	// failed trial request opens the circuit again
+	now = now.Add(time.Minute)
+	_, _ = c.Get(ctx, server.URL, nil)
+	if state := c.Circuits()[host]; state != CircuitOpen || calls.Load() != 3 {
+		t.Errorf("state after failed trial = %v after %d calls, want open after 3", state, calls.Load())
+	}
+
+	// successful trial closes it
+	now = now.Add(time.Minute)
+	_, _ = c.Get(ctx, server.URL, nil) // 500
+	now = now.Add(time.Minute)
+	resp, err := c.Get(ctx, server.URL, nil)
+	if err != nil || resp.StatusCode != 200 || c.Circuits()[host] != CircuitClosed {
+		t.Errorf("Get() after recovery = %v, %v, state %v, want closed", resp, err, c.Circuits()[host])
+	}
+
+	want := "open half-open open half-open open half-open closed"
+	if got := strings.Join(transitions, " "); got != want {
+		t.Errorf("reported transitions %q, want %q", got, want)
+	}
+}
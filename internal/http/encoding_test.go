@@ -0,0 +1,89 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+func compress(t *testing.T, newWriter func(io.Writer) io.WriteCloser, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestClient_DecodesContentEncoding tests that compressed responses are decoded
+func TestClient_DecodesContentEncoding(t *testing.T) {
+	const page = "<html><body>Wohnung</body></html>"
+	gzipped := compress(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, page)
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{"identity", "", []byte(page)},
+		{"gzip", "gzip", gzipped},
+		{"zlib deflate", "deflate", compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }, page)},
+		{"raw deflate", "deflate", compress(t, func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		}, page)},
+		{"brotli", "br", compress(t, func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }, page)},
+		{"gzip then brotli", "gzip, br", compress(t, func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }, string(gzipped))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				_, _ = w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			c := NewClient(time.Second)
+			resp, err := c.Get(context.Background(), server.URL, map[string]string{"Accept-Encoding": "gzip, deflate, br"})
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			if string(resp.Body) != page {
+				t.Errorf("Get() body = %q, want %q", resp.Body, page)
+			}
+			if http.Header(resp.Headers).Get("Content-Encoding") != "" {
+				t.Errorf("Content-Encoding header still set after decoding")
+			}
+		})
+	}
+}
+
+// TestClient_UnsupportedContentEncoding tests that unknown encodings are reported
+func TestClient_UnsupportedContentEncoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "zstd")
+		_, _ = w.Write([]byte("compressed"))
+	}))
+	defer server.Close()
+
+	c := NewClient(time.Second)
+	if _, err := c.Get(context.Background(), server.URL, nil); err == nil {
+		t.Error("Get() expected error for unsupported encoding")
+	}
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit allows a request every Interval on average, with up to Burst at once
+type RateLimit struct {
+	Interval time.Duration
+	Burst    int
+}
+
+// limiter holds a token bucket per host, shared by everything using the client
+type limiter struct {
+	defaultLimit RateLimit
+	limits       map[string]RateLimit
+	now          func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time // set from Retry-After
+}
+
+// WithRateLimit spaces out requests per host. Hosts without an entry in perHost use
+// defaultLimit, a zero Interval does not limit.
+func WithRateLimit(defaultLimit RateLimit, perHost map[string]RateLimit) Option {
+	return func(c *Client) {
+		limits := make(map[string]RateLimit, len(perHost))
+		for host, limit := range perHost {
+			limits[strings.ToLower(host)] = limit
+		}
+		c.limiter = &limiter{defaultLimit: defaultLimit, limits: limits, now: time.Now, buckets: map[string]*bucket{}}
+	}
+}
+
+// wait blocks until a request to the host may be sent
+func (l *limiter) wait(ctx context.Context, host string, sleep func(context.Context, time.Duration) error) error {
+	if l == nil {
+		return nil
+	}
+	if delay := l.reserve(host); delay > 0 {
+		return sleep(ctx, delay)
+	}
+	return nil
+}
+
+// reserve takes a token from the host's bucket and returns how long to wait for it
+func (l *limiter) reserve(host string) time.Duration {
+	limit, ok := l.limits[host]
+	if !ok {
+		limit = l.defaultLimit
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: float64(max(limit.Burst, 1)), last: now}
+		l.buckets[host] = b
+	}
+
+	var delay time.Duration
+	if limit.Interval > 0 {
+		burst := float64(max(limit.Burst, 1))
+		b.tokens = min(burst, b.tokens+float64(now.Sub(b.last))/float64(limit.Interval))
+		b.last = now
+		b.tokens--
+		if b.tokens < 0 {
+			delay = time.Duration(-b.tokens * float64(limit.Interval))
+		}
+	}
+	if paused := b.pausedUntil.Sub(now); paused > delay {
+		delay = paused
+	}
+	return delay
+}
+
+// pause holds back requests to the host for the given duration
+func (l *limiter) pause(host string, d time.Duration) {
+	if l == nil || d <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until := l.now().Add(d)
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{last: l.now()}
+		l.buckets[host] = b
+	}
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+		log.Printf("[http] %s asked to retry after %s, pausing requests", host, d)
+	}
+}
+
+// retryAfter returns the delay requested by a 429 or 503 response, zero if none
+func retryAfter(resp *HTTPResponse, now time.Time) time.Duration {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0
+	}
+	value := http.Header(resp.Headers).Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
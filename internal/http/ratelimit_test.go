@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestLimiter_Reserve tests the token bucket per host
+func TestLimiter_Reserve(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	var c Client
+	WithRateLimit(RateLimit{Interval: 2 * time.Second, Burst: 2}, map[string]RateLimit{"WWW.WBM.DE": {Interval: 10 * time.Second, Burst: 1}})(&c)
+	l := c.limiter
+	l.now = func() time.Time { return now }
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		host    string
+		want    time.Duration
+	}{
+		{"burst", 0, "www.howoge.de", 0},
+		{"burst", 0, "www.howoge.de", 0},
+		{"bucket empty", 0, "www.howoge.de", 2 * time.Second},
+		{"queued behind reservation", 0, "www.howoge.de", 4 * time.Second},
+		{"other host unaffected", 0, "www.gewobag.de", 0},
+		{"refilled", 10 * time.Second, "www.howoge.de", 0},
+		{"per host limit", 0, "www.wbm.de", 0},
+		{"per host interval", time.Second, "www.wbm.de", 9 * time.Second},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if got := l.reserve(step.host); got != step.want {
+			t.Errorf("%s: reserve(%s) = %v, want %v", step.name, step.host, got, step.want)
+		}
+	}
+
+	l.pause("www.gewobag.de", time.Minute)
+	if got := l.reserve("www.gewobag.de"); got != time.Minute {
+		t.Errorf("reserve() after pause = %v, want 1m", got)
+	}
+}
+
+// TestRetryAfter tests reading Retry-After from 429 and 503 responses
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		status int
+		value  string
+		want   time.Duration
+	}{
+		{"seconds", 429, "30", 30 * time.Second},
+		{"http date", 503, now.Add(2 * time.Minute).Format(http.TimeFormat), 2 * time.Minute},
+		{"date in the past", 503, now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"other status", 500, "30", 0},
+		{"missing", 429, "", 0},
+		{"invalid", 429, "soon", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &HTTPResponse{StatusCode: tt.status, Headers: map[string][]string{}}
+			if tt.value != "" {
+				resp.Headers["Retry-After"] = []string{tt.value}
+			}
+			if got := retryAfter(resp, now); got != tt.want {
+				t.Errorf("retryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClient_HonoursRetryAfter tests that retries wait as long as the server asks
+func TestClient_HonoursRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		wantCalls  int
+		wantSleeps []time.Duration
+	}{
+		{"short delay is waited for", "3", 2, []time.Duration{3 * time.Second}},
+		{"long delay ends the retries", "600", 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+				}
+			}))
+			defer server.Close()
+
+			c := NewClient(time.Second, WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Minute}))
+			var sleeps []time.Duration
+			c.sleep = func(_ context.Context, d time.Duration) error {
+				sleeps = append(sleeps, d)
+				return nil
+			}
+
+			_, _ = c.Get(context.Background(), server.URL, nil)
+			if calls != tt.wantCalls || len(sleeps) != len(tt.wantSleeps) {
+				t.Fatalf("%d calls with sleeps %v, want %d calls with %v", calls, sleeps, tt.wantCalls, tt.wantSleeps)
+			}
+			for i := range sleeps {
+				if sleeps[i] != tt.wantSleeps[i] {
+					t.Errorf("sleep %d = %v, want %v", i, sleeps[i], tt.wantSleeps[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package replay records the responses of an HTTP client to fixture files and replays
+// them, so scrapers can be tested against real pages without network access.
+//
+// Fixtures and golden files are recorded again against the live endpoints with
+//
+//	go test ./internal/scraping/companies/... -refresh
+package replay
+
+import (
+	"apartmenthunter/internal/http"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoInteraction is returned by a Player for requests missing from its fixture
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// Interaction is one recorded request and its response
+type Interaction struct {
+	Method     string              `json:"method"`
+	URL        string              `json:"url"`
+	Request    string              `json:"request,omitempty"` // form or JSON body
+	StatusCode int                 `json:"status"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Body       string              `json:"body"`
+}
+
+func (i Interaction) key() string {
+	return i.Method + " " + i.URL + " " + i.Request
+}
+
+// Recorder passes requests on to a live client and keeps the responses
+type Recorder struct {
+	client http.HTTPClient
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder records the responses of client
+func NewRecorder(client http.HTTPClient) *Recorder {
+	return &Recorder{client: client}
+}
+
+func (r *Recorder) Get(ctx context.Context, url string, headers map[string]string) (*http.HTTPResponse, error) {
+	resp, err := r.client.Get(ctx, url, headers)
+	return r.record(Interaction{Method: "GET", URL: url}, resp, err)
+}
+
+func (r *Recorder) Post(ctx context.Context, url string, formData map[string][]string, headers map[string]string) (*http.HTTPResponse, error) {
+	resp, err := r.client.Post(ctx, url, formData, headers)
+	return r.record(Interaction{Method: "POST", URL: url, Request: encodeForm(formData)}, resp, err)
+}
+
+func (r *Recorder) PostJSON(ctx context.Context, url string, jsonBody []byte, headers map[string]string) (*http.HTTPResponse, error) {
+	resp, err := r.client.PostJSON(ctx, url, jsonBody, headers)
+	return r.record(Interaction{Method: "POST", URL: url, Request: string(jsonBody)}, resp, err)
+}
+
+// record keeps a response, failed requests are not recorded
+func (r *Recorder) record(interaction Interaction, resp *http.HTTPResponse, err error) (*http.HTTPResponse, error) {
+	if err != nil {
+		return resp, err
+	}
+	interaction.StatusCode = resp.StatusCode
+	interaction.Body = string(resp.Body)
+	for key, values := range resp.Headers {
+		if key == "Set-Cookie" {
+			continue // session cookies do not belong in fixtures
+		}
+		if interaction.Headers == nil {
+			interaction.Headers = make(map[string][]string)
+		}
+		interaction.Headers[key] = values
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Interactions returns the recorded interactions in the order of the requests
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Save writes the recorded interactions to a fixture file
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Player answers requests with recorded responses. Repeated requests get the recorded
+// responses in order, the last one is repeated once they are used up.
+type Player struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+	served       map[string]int
+}
+
+// NewPlayer replays the given interactions
+func NewPlayer(interactions []Interaction) *Player {
+	p := &Player{
+		interactions: make(map[string][]Interaction),
+		served:       make(map[string]int),
+	}
+	for _, interaction := range interactions {
+		p.interactions[interaction.key()] = append(p.interactions[interaction.key()], interaction)
+	}
+	return p
+}
+
+// Load replays the interactions of a fixture file
+func Load(path string) (*Player, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("parsing fixture %s: %w", path, err)
+	}
+	return NewPlayer(interactions), nil
+}
+
+func (p *Player) Get(ctx context.Context, url string, headers map[string]string) (*http.HTTPResponse, error) {
+	return p.replay(ctx, Interaction{Method: "GET", URL: url})
+}
+
+func (p *Player) Post(ctx context.Context, url string, formData map[string][]string, headers map[string]string) (*http.HTTPResponse, error) {
+	return p.replay(ctx, Interaction{Method: "POST", URL: url, Request: encodeForm(formData)})
+}
+
+func (p *Player) PostJSON(ctx context.Context, url string, jsonBody []byte, headers map[string]string) (*http.HTTPResponse, error) {
+	return p.replay(ctx, Interaction{Method: "POST", URL: url, Request: string(jsonBody)})
+}
+
+func (p *Player) replay(ctx context.Context, request Interaction) (*http.HTTPResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key := request.key()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	recorded := p.interactions[key]
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, request.Method, request.URL)
+	}
+	i := min(p.served[key], len(recorded)-1)
+	p.served[key]++
+
+	interaction := recorded[i]
+	return &http.HTTPResponse{
+		StatusCode: interaction.StatusCode,
+		Body:       []byte(interaction.Body),
+		Headers:    interaction.Headers,
+	}, nil
+}
+
+// encodeForm encodes form data with sorted keys, like the request body the client sends
+func encodeForm(formData map[string][]string) string {
+	return url.Values(formData).Encode()
+}
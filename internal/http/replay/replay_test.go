@@ -0,0 +1,113 @@
+package replay
+
+import (
+	"apartmenthunter/internal/http"
+	"context"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRecordAndReplay tests that recorded responses are replayed in order
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		calls++
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Content-Type", "text/html")
+		_ = r.ParseForm()
+		fmt.Fprintf(w, "%s %s page=%s call=%d", r.Method, r.URL.Path, r.Form.Get("page"), calls)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	recorder := NewRecorder(http.NewClient(time.Second))
+	requests := []func(c http.HTTPClient) (*http.HTTPResponse, error){
+		func(c http.HTTPClient) (*http.HTTPResponse, error) { return c.Get(ctx, server.URL+"/list", nil) },
+		func(c http.HTTPClient) (*http.HTTPResponse, error) { return c.Get(ctx, server.URL+"/list", nil) },
+		func(c http.HTTPClient) (*http.HTTPResponse, error) {
+			return c.Post(ctx, server.URL+"/search", map[string][]string{"page": {"2"}}, nil)
+		},
+		func(c http.HTTPClient) (*http.HTTPResponse, error) {
+			return c.PostJSON(ctx, server.URL+"/api", []byte(`{"offset":0}`), nil)
+		},
+	}
+	var recorded []string
+	for _, request := range requests {
+		resp, err := request(recorder)
+		if err != nil {
+			t.Fatalf("recording: unexpected error: %v", err)
+		}
+		recorded = append(recorded, string(resp.Body))
+	}
+
+	path := filepath.Join(t.TempDir(), "testdata", "fixtures.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	player, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	for i, request := range requests {
+		resp, err := request(player)
+		if err != nil {
+			t.Fatalf("replay %d: unexpected error: %v", i, err)
+		}
+		if string(resp.Body) != recorded[i] {
+			t.Errorf("replay %d: body = %q, want %q", i, resp.Body, recorded[i])
+		}
+		if _, ok := resp.Headers["Set-Cookie"]; ok {
+			t.Errorf("replay %d: cookies were recorded", i)
+		}
+		if resp.Headers["Content-Type"][0] != "text/html" {
+			t.Errorf("replay %d: Content-Type = %v", i, resp.Headers["Content-Type"])
+		}
+	}
+
+	// used up responses repeat the last one
+	resp, err := player.Get(ctx, server.URL+"/list", nil)
+	if err != nil || string(resp.Body) != recorded[1] {
+		t.Errorf("repeated Get() = %v, %v, want %q", resp, err, recorded[1])
+	}
+	if calls != len(requests) {
+		t.Errorf("server got %d requests, want %d", calls, len(requests))
+	}
+}
+
+// TestPlayer_Unrecorded tests that requests missing from the fixture fail
+func TestPlayer_Unrecorded(t *testing.T) {
+	player := NewPlayer([]Interaction{
+		{Method: "POST", URL: "https://example.com/search", Request: "page=1", StatusCode: 200, Body: "one"},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		do   func() (*http.HTTPResponse, error)
+	}{
+		{"other url", func() (*http.HTTPResponse, error) { return player.Get(ctx, "https://example.com/other", nil) }},
+		{"other method", func() (*http.HTTPResponse, error) { return player.Get(ctx, "https://example.com/search", nil) }},
+		{"other form", func() (*http.HTTPResponse, error) {
+			return player.Post(ctx, "https://example.com/search", map[string][]string{"page": {"2"}}, nil)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.do(); !errors.Is(err, ErrNoInteraction) {
+				t.Errorf("error = %v, want ErrNoInteraction", err)
+			}
+		})
+	}
+
+	resp, err := player.Post(ctx, "https://example.com/search", map[string][]string{"page": {"1"}}, nil)
+	if err != nil || string(resp.Body) != "one" {
+		t.Errorf("Post() = %v, %v, want recorded response", resp, err)
+	}
+}
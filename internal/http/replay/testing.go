@@ -0,0 +1,83 @@
+package replay
+
+import (
+	"apartmenthunter/internal/http"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var refresh = flag.Bool("refresh", false, "record replay fixtures and golden files against the live endpoints")
+
+var live = flag.Bool("live", false, "run the tests against the live endpoints")
+
+// SkipUnlessLive skips tests that need the live endpoints unless run with -live or -refresh
+func SkipUnlessLive(t testing.TB) {
+	t.Helper()
+	if !*live && !*refresh {
+		t.Skip("skipping live test, run with -live")
+	}
+}
+
+// Refreshing reports whether the tests were run with -refresh
+func Refreshing() bool {
+	return *refresh
+}
+
+// Client returns a client replaying the fixture at path. With -refresh the requests go to
+// live instead and the fixture is rewritten when the test finishes.
+func Client(t testing.TB, path string, live http.HTTPClient) http.HTTPClient {
+	t.Helper()
+	if !*refresh {
+		player, err := Load(path)
+		if err != nil {
+			t.Fatalf("loading fixture (run with -refresh to record it): %v", err)
+		}
+		return player
+	}
+
+	recorder := NewRecorder(live)
+	t.Cleanup(func() {
+		if t.Failed() {
+			return // keep the old fixture
+		}
+		if err := recorder.Save(path); err != nil {
+			t.Errorf("saving fixture: %v", err)
+		}
+	})
+	return recorder
+}
+
+// Golden compares got with the golden file at path, with -refresh the file is rewritten
+func Golden(t testing.TB, path string, got []byte) {
+	t.Helper()
+	if *refresh {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("creating golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -refresh to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("result differs from %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// Lines formats every item on its own line with its field names, for golden files
+func Lines[T any](items []T) []byte {
+	var buf bytes.Buffer
+	for _, item := range items {
+		fmt.Fprintf(&buf, "%+v\n", item)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how often failed requests are repeated. GET requests are
+// idempotent and always retried, POST requests only if RetryPOST is set.
+type RetryPolicy struct {
+	MaxAttempts int           // including the first attempt
+	BaseDelay   time.Duration // before the second attempt, doubled for every further one
+	MaxDelay    time.Duration
+	RetryPOST   bool
+}
+
+// WithRetry retries failed requests with exponential backoff and jitter
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// backoff returns the delay after the given failed attempt, a random value between half
+// and the full exponential delay so that scrapers do not retry in lockstep
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay <= 0) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retryable reports whether a request failed in a way that may succeed when repeated:
+// network errors, timeouts, rate limiting and server errors
+func retryable(resp *HTTPResponse, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
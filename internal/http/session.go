@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+)
+
+// Session is a client with its own cookie jar for sites that set session or consent
+// cookies on the first visit. It visits the landing page before the first request and
+// starts over with fresh cookies when the site answers 403. Rate limits and circuit
+// breakers are shared with the client the session was created from.
+type Session struct {
+	base       *Client
+	landingURL string
+	headers    map[string]string
+
+	mu     sync.Mutex
+	client *Client // with the current cookie jar
+	warmed bool
+}
+
+// NewSession creates a session that warms up by requesting landingURL with the given
+// headers, e.g. a browser User-Agent
+func (c *Client) NewSession(landingURL string, headers map[string]string) *Session {
+	s := &Session{base: c, landingURL: landingURL, headers: headers}
+	s.reset()
+	return s
+}
+
+func (s *Session) Get(ctx context.Context, url string, headers map[string]string) (*HTTPResponse, error) {
+	return s.do(ctx, func(c *Client) (*HTTPResponse, error) { return c.Get(ctx, url, headers) })
+}
+
+func (s *Session) Post(ctx context.Context, url string, formData map[string][]string, headers map[string]string) (*HTTPResponse, error) {
+	return s.do(ctx, func(c *Client) (*HTTPResponse, error) { return c.Post(ctx, url, formData, headers) })
+}
+
+func (s *Session) PostJSON(ctx context.Context, url string, jsonBody []byte, headers map[string]string) (*HTTPResponse, error) {
+	return s.do(ctx, func(c *Client) (*HTTPResponse, error) { return c.PostJSON(ctx, url, jsonBody, headers) })
+}
+
+// do warms the session up if needed and repeats the request once with a new session if
+// the site rejects it with 403
+func (s *Session) do(ctx context.Context, request func(c *Client) (*HTTPResponse, error)) (*HTTPResponse, error) {
+	for attempt := 1; ; attempt++ {
+		client, err := s.warmUp(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := request(client)
+		if err != nil || resp.StatusCode != http.StatusForbidden {
+			return resp, err
+		}
+
+		log.Printf("[http] 403 from %s, resetting session", hostOf(s.landingURL))
+		s.mu.Lock()
+		if s.client == client {
+			s.reset()
+		}
+		s.mu.Unlock()
+		if attempt == 2 {
+			return resp, nil
+		}
+	}
+}
+
+// warmUp visits the landing page once per session and returns the client to use
+func (s *Session) warmUp(ctx context.Context) (*Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.warmed {
+		return s.client, nil
+	}
+	resp, err := s.client.Get(ctx, s.landingURL, s.headers)
+	if err != nil {
+		return nil, fmt.Errorf("warming up session: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("warming up session: status %d from %s", resp.StatusCode, s.landingURL)
+	}
+	s.warmed = true
+	return s.client, nil
+}
+
+// reset drops all cookies so that the next request warms up again, callers hold mu
+func (s *Session) reset() {
+	jar, _ := cookiejar.New(nil) // never fails without options
+	httpClient := *s.base.httpClient
+	httpClient.Jar = jar
+	client := *s.base
+	client.httpClient = &httpClient
+	s.client = &client
+	s.warmed = false
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestSession tests warming up, sending cookies and resetting on 403
+func TestSession(t *testing.T) {
+	var requests []string
+	sessions := 0
+	rejectNext := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, _ := r.Cookie("session")
+		value := ""
+		if cookie != nil {
+			value = cookie.Value
+		}
+		requests = append(requests, r.URL.Path+" "+value)
+
+		switch {
+		case r.URL.Path == "/landing":
+			sessions++
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: string(rune('0' + sessions))})
+		case value == "" || rejectNext:
+			rejectNext = false
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second)
+	session := client.NewSession(server.URL+"/landing", map[string]string{"User-Agent": "test"})
+	ctx := context.Background()
+
+	if resp, err := session.Get(ctx, server.URL+"/search", nil); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("Get() = %v, %v, want 200", resp, err)
+	}
+	if resp, err := session.Post(ctx, server.URL+"/search", nil, nil); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("Post() = %v, %v, want 200", resp, err)
+	}
+	rejectNext = true
+	if resp, err := session.Get(ctx, server.URL+"/search", nil); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("Get() after 403 = %v, %v, want 200 with a new session", resp, err)
+	}
+
+	want := []string{"/landing ", "/search 1", "/search 1", "/search 1", "/landing ", "/search 2"}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+
+	// the client the session was created from keeps no cookies
+	if resp, _ := client.Get(ctx, server.URL+"/search", nil); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("client without session got status %d, want 403", resp.StatusCode)
+	}
+}
+
+// TestSession_WarmUpFails tests that requests fail if the landing page cannot be loaded
+func TestSession_WarmUpFails(t *testing.T) {
+	server, calls := statusServer(t, http.StatusServiceUnavailable)
+	session := NewClient(time.Second).NewSession(server.URL, nil)
+
+	if _, err := session.Get(context.Background(), server.URL+"/search", nil); err == nil {
+		t.Error("Get() should fail if the warm-up fails")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("%d calls, want only the warm-up request", calls.Load())
+	}
+}
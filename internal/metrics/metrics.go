@@ -0,0 +1,174 @@
+// Package metrics exposes Prometheus metrics of the scrapers and notifications.
+package metrics
+
+import (
+	"apartmenthunter/internal/http"
+	"apartmenthunter/internal/notify"
+	"apartmenthunter/internal/scraping/common"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "apartmenthunter"
+
+// scrape results
+const (
+	resultSuccess   = "success"
+	resultUnchanged = "unchanged"
+	resultError     = "error"
+)
+
+// Metrics are the collectors of the bot, registered on their own registry
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	scrapeDuration *prometheus.HistogramVec
+	httpResponses  *prometheus.CounterVec
+	listings       *prometheus.GaugeVec
+	newListings    *prometheus.CounterVec
+	matches        *prometheus.CounterVec
+	notifications  *prometheus.CounterVec
+	lastSuccess    *prometheus.GaugeVec
+	circuits       *prometheus.GaugeVec
+
+	scrapers sync.Map // scraper name by listing company, e.g. "Stadt Und Land" -> "StadtUndLand"
+}
+
+// New creates and registers the metrics, together with the Go runtime and process metrics
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		scrapeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "scrape_duration_seconds",
+			Help:      "Duration of scrapes, including retries and rate limiting.",
+			Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120},
+		}, []string{"scraper", "result"}),
+		httpResponses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_responses_total",
+			Help:      "HTTP responses received by scrapers by status code, \"error\" for failed requests.",
+		}, []string{"scraper", "code"}),
+		listings: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "scrape_listings",
+			Help:      "Listings returned by the last parsed scrape.",
+		}, []string{"scraper"}),
+		newListings: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "new_listings_total",
+			Help:      "Listings not seen before.",
+		}, []string{"scraper"}),
+		matches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "matches_total",
+			Help:      "New listings matching the filters of a user.",
+		}, []string{"scraper", "user"}),
+		notifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifications_total",
+			Help:      "Match notifications by scraper and delivery status.",
+		}, []string{"scraper", "status"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Unix time of the last successful scrape, unchanged pages included.",
+		}, []string{"scraper"}),
+		circuits: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "circuit_state",
+			Help:      "Circuit breaker state of a host: 0 closed, 1 open, 2 half-open.",
+		}, []string{"host"}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.scrapeDuration, m.httpResponses, m.listings, m.newListings, m.matches, m.notifications, m.lastSuccess, m.circuits,
+	)
+	return m
+}
+
+// ObserveScrape records the duration and outcome of a scrape
+func (m *Metrics) ObserveScrape(scraper string, duration time.Duration, listings []common.Listing, err error) {
+	result := resultSuccess
+	switch {
+	case errors.Is(err, common.ErrUnchanged):
+		result = resultUnchanged
+	case err != nil:
+		result = resultError
+	default:
+		m.listings.WithLabelValues(scraper).Set(float64(len(listings)))
+		for _, listing := range listings {
+			m.scrapers.Store(listing.Company, scraper)
+		}
+	}
+	m.scrapeDuration.WithLabelValues(scraper, result).Observe(duration.Seconds())
+	if result != resultError {
+		m.lastSuccess.WithLabelValues(scraper).SetToCurrentTime()
+	}
+}
+
+// ObserveCircuit records the circuit breaker state of a host
+func (m *Metrics) ObserveCircuit(host string, state http.CircuitState) {
+	m.circuits.WithLabelValues(host).Set(float64(state))
+}
+
+// NewListing counts a listing not seen before
+func (m *Metrics) NewListing(scraper string) {
+	m.newListings.WithLabelValues(scraper).Inc()
+}
+
+// Match counts a new listing matching the filters of a user
+func (m *Metrics) Match(scraper, userID string) {
+	m.matches.WithLabelValues(scraper, userID).Inc()
+}
+
+// RecordNotification counts match notifications, so Metrics can be used as notify.Recorder.
+// The scraper is looked up by the company of the listing as seen by ObserveScrape.
+func (m *Metrics) RecordNotification(userID string, listing common.Listing, status notify.Status) {
+	scraper := listing.Company
+	if name, ok := m.scrapers.Load(listing.Company); ok {
+		scraper = name.(string)
+	}
+	m.notifications.WithLabelValues(scraper, string(status)).Inc()
+}
+
+// Wrap returns a client counting the status codes of the responses a scraper receives
+func (m *Metrics) Wrap(scraper string, client http.HTTPClient) http.HTTPClient {
+	return &countingClient{client: client, responses: m.httpResponses.MustCurryWith(prometheus.Labels{"scraper": scraper})}
+}
+
+type countingClient struct {
+	client    http.HTTPClient
+	responses *prometheus.CounterVec
+}
+
+func (c *countingClient) Get(ctx context.Context, url string, headers map[string]string) (*http.HTTPResponse, error) {
+	resp, err := c.client.Get(ctx, url, headers)
+	return c.count(resp, err)
+}
+
+func (c *countingClient) Post(ctx context.Context, url string, formData map[string][]string, headers map[string]string) (*http.HTTPResponse, error) {
+	resp, err := c.client.Post(ctx, url, formData, headers)
+	return c.count(resp, err)
+}
+
+func (c *countingClient) PostJSON(ctx context.Context, url string, jsonBody []byte, headers map[string]string) (*http.HTTPResponse, error) {
+	resp, err := c.client.PostJSON(ctx, url, jsonBody, headers)
+	return c.count(resp, err)
+}
+
+func (c *countingClient) count(resp *http.HTTPResponse, err error) (*http.HTTPResponse, error) {
+	code := "error"
+	if err == nil && resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	c.responses.WithLabelValues(code).Inc()
+	return resp, err
+}
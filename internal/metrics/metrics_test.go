@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"apartmenthunter/internal/http"
+	"apartmenthunter/internal/http/mock"
+	"apartmenthunter/internal/notify"
+	"apartmenthunter/internal/scraping/common"
+	"context"
+	"errors"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrapeMetrics(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(nethttp.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+// TestMetrics tests that scrapes, responses and notifications show up on /metrics
+func TestMetrics(t *testing.T) {
+	m := New()
+	ctx := context.Background()
+
+	client := mock.NewHTTPClient()
+	calls := 0
+	client.GetFunc = func(ctx context.Context, url string, headers map[string]string) (*http.HTTPResponse, error) {
+		calls++
+		if calls == 3 {
+			return nil, errors.New("timeout")
+		}
+		return &http.HTTPResponse{StatusCode: 200 + 303*(calls-1)}, nil
+	}
+	wrapped := m.Wrap("WBM", client)
+	for i := 0; i < 3; i++ {
+		_, _ = wrapped.Get(ctx, "https://www.wbm.de/", nil)
+	}
+
+	m.ObserveScrape("WBM", 2*time.Second, make([]common.Listing, 3), nil)
+	m.ObserveScrape("WBM", time.Second, nil, common.ErrUnchanged)
+	m.ObserveScrape("Howoge", 40*time.Second, nil, errors.New("timeout"))
+	m.NewListing("WBM")
+	m.NewListing("WBM")
+	m.ObserveCircuit("www.wbm.de", http.CircuitOpen)
+	m.Match("WBM", "alice")
+	notify.Recorders{m}.RecordNotification("alice", common.Listing{Company: "WBM"}, notify.StatusSent)
+	m.RecordNotification("bob", common.Listing{Company: "WBM"}, notify.StatusFailed)
+	m.ObserveScrape("StadtUndLand", time.Second, []common.Listing{{Company: "Stadt Und Land"}}, nil)
+	m.RecordNotification("alice", common.Listing{Company: "Stadt Und Land"}, notify.StatusQueued)
+
+	body := scrapeMetrics(t, m)
+	tests := []string{
+		`apartmenthunter_http_responses_total{code="200",scraper="WBM"} 1`,
+		`apartmenthunter_http_responses_total{code="503",scraper="WBM"} 1`,
+		`apartmenthunter_http_responses_total{code="error",scraper="WBM"} 1`,
+		`apartmenthunter_scrape_duration_seconds_count{result="success",scraper="WBM"} 1`,
+		`apartmenthunter_scrape_duration_seconds_count{result="unchanged",scraper="WBM"} 1`,
+		`apartmenthunter_scrape_duration_seconds_bucket{result="error",scraper="Howoge",le="30"} 0`,
+		`apartmenthunter_scrape_listings{scraper="WBM"} 3`,
+		`apartmenthunter_new_listings_total{scraper="WBM"} 2`,
+		`apartmenthunter_matches_total{scraper="WBM",user="alice"} 1`,
+		`apartmenthunter_notifications_total{scraper="WBM",status="sent"} 1`,
+		`apartmenthunter_notifications_total{scraper="WBM",status="failed"} 1`,
+		`apartmenthunter_notifications_total{scraper="StadtUndLand",status="queued"} 1`,
+		`apartmenthunter_last_success_timestamp_seconds{scraper="WBM"}`,
+		`apartmenthunter_circuit_state{host="www.wbm.de"} 1`,
+		`go_goroutines`,
+	}
+	for _, want := range tests {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics lack %q", want)
+		}
+	}
+	if strings.Contains(body, `apartmenthunter_last_success_timestamp_seconds{scraper="Howoge"}`) {
+		t.Error("failed scrape set the last success timestamp")
+	}
+}
@@ -0,0 +1,34 @@
+// Package normalize spells out German text so that differently written names and addresses
+// compare equal. It imports nothing internal, so every package can use it.
+package normalize
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	umlautReplacer = strings.NewReplacer(
+		"ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss",
+		"Ä", "ae", "Ö", "oe", "Ü", "ue",
+	)
+	streetSuffix = regexp.MustCompile(`str(\.|\b)`)
+)
+
+// Umlauts spells out German umlauts and ß, "Köpenicker Straße" becomes "Koepenicker Strasse"
+// apart from capital umlauts, which are lowercased
+func Umlauts(text string) string {
+	return umlautReplacer.Replace(text)
+}
+
+// Text lowercases a text, spells out German umlauts and collapses whitespace,
+// so that "Für Studierende" and "fuer  studierende" compare equal.
+func Text(text string) string {
+	return strings.Join(strings.Fields(Umlauts(strings.ToLower(text))), " ")
+}
+
+// Street normalizes like Text and spells out "Str.", so "Karl-Marx-Str." and
+// "Karl-Marx-Straße" compare equal
+func Street(street string) string {
+	return streetSuffix.ReplaceAllString(Text(street), "strasse")
+}
@@ -0,0 +1,46 @@
+package normalize
+
+import "testing"
+
+// TestText tests lowercasing, umlaut replacement and whitespace handling
+func TestText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Nur für Studierende", "nur fuer studierende"},
+		{"  GROẞE   Wohnung\n mit Balkon ", "grosse wohnung mit balkon"},
+		{"ÄÖÜ", "aeoeue"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := Text(tt.input); result != tt.expected {
+				t.Errorf("Text(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestStreet tests spelling out street suffixes
+func TestStreet(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Karl-Marx-Str. 86", "karl-marx-strasse 86"},
+		{"Karl-Marx-Straße 86", "karl-marx-strasse 86"},
+		{"Weserstr 5", "weserstrasse 5"},
+		{"Strasse des 17. Juni", "strasse des 17. juni"},
+		{"Sonnenallee 300", "sonnenallee 300"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := Street(tt.input); result != tt.expected {
+				t.Errorf("Street(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package notify
+
+import (
+	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/telegram"
+	"apartmenthunter/internal/users"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// maxBatchSize keeps batched messages below telegram's message length limit
+const maxBatchSize = 10
+
+// drainTimeout bounds the delivery of queued listings on shutdown
+const drainTimeout = 10 * time.Second
+
+// Sender delivers an HTML message, implemented by telegram.Client
+type Sender interface {
+	SendMessageWithOptions(ctx context.Context, htmlMessage string, opts telegram.MessageOptions) error
+}
+
+// Status is the delivery state of a notification
+type Status string
+
+const (
+	StatusSent   Status = "sent"
+	StatusQueued Status = "queued" // held back during quiet hours
+	StatusFailed Status = "failed"
+)
+
+// Recorder is told about the delivery of every matching listing, e.g. to export matches
+type Recorder interface {
+	RecordNotification(userID string, listing common.Listing, status Status)
+}
+
+// Recorders tells each of several recorders about a delivery
+type Recorders []Recorder
+
+func (r Recorders) RecordNotification(userID string, listing common.Listing, status Status) {
+	for _, recorder := range r {
+		recorder.RecordNotification(userID, listing, status)
+	}
+}
+
+// Dispatcher sends listing notifications to users, holding back non-urgent
+// listings during a user's quiet hours and delivering them as a batch afterwards
+type Dispatcher struct {
+	sender   Sender
+	location *time.Location
+	now      func() time.Time
+	Recorder Recorder // optional, not told about near matches
+
+	mu     sync.Mutex
+	queues map[string]*queue
+}
+
+type queue struct {
+	user    users.UserConfig
+	entries []entry
+}
+
+type entry struct {
+	listing common.Listing
+	info    *telegram.TelegramInfo
+	match   bool // reported to the recorder
+}
+
+func NewDispatcher(sender Sender, location *time.Location) *Dispatcher {
+	if location == nil {
+		location = time.Local
+	}
+	return &Dispatcher{
+		sender:   sender,
+		location: location,
+		now:      time.Now,
+		queues:   make(map[string]*queue),
+	}
+}
+
+// Notify sends a listing to the user right away, or queues it if the user is in quiet hours
+// and the listing is not urgent
+func (d *Dispatcher) Notify(ctx context.Context, user *users.UserConfig, listing common.Listing) error {
+	info := listing.ToTelegramInfo()
+	if score, ok := listing.Score(user); ok {
+		info.Score = fmt.Sprintf("%.0f/100", score)
+	}
+	info.Notes = append(info.Notes, listing.CommuteNotes(user)...)
+	return d.deliver(ctx, user, entry{listing, info, true}, isUrgent(user, listing))
+}
+
+// NotifyNearMatch sends a listing labelled as near match together with the criteria it missed,
+// near matches are never urgent
+func (d *Dispatcher) NotifyNearMatch(ctx context.Context, user *users.UserConfig, listing common.Listing, misses []common.Miss) error {
+	info := listing.ToTelegramInfo()
+	info.Label = "Near match"
+	for _, miss := range misses {
+		info.Notes = append(info.Notes, miss.Detail)
+	}
+	info.Notes = append(info.Notes, listing.CommuteNotes(user)...)
+	return d.deliver(ctx, user, entry{listing, info, false}, false)
+}
+
+// NotifyUpdate sends a change of an already known listing, e.g. a price drop, labelled
+// with what happened
+func (d *Dispatcher) NotifyUpdate(ctx context.Context, user *users.UserConfig, listing common.Listing, label, note string) error {
+	info := listing.ToTelegramInfo()
+	info.Label = label
+	if note != "" {
+		info.Notes = append([]string{note}, info.Notes...)
+	}
+	info.Notes = append(info.Notes, listing.CommuteNotes(user)...)
+	return d.deliver(ctx, user, entry{listing, info, true}, isUrgent(user, listing))
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, user *users.UserConfig, e entry, urgent bool) error {
+	if d.inQuietHours(user, d.now()) {
+		if !urgent {
+			d.enqueue(user, e)
+			d.record(user.UserID, e, StatusQueued)
+			log.Printf("[notify] quiet hours for user %q, queued listing %s", user.UserID, e.listing.ID)
+			return nil
+		}
+		log.Printf("[notify] urgent listing %s for user %q during quiet hours", e.listing.ID, user.UserID)
+	}
+
+	opts := telegram.MessageOptions{ChatID: user.ChatID}
+	err := d.sender.SendMessageWithOptions(ctx, telegram.BuildHTML(e.info), opts)
+	d.record(user.UserID, e, statusOf(err))
+	return err
+}
+
+func (d *Dispatcher) record(userID string, e entry, status Status) {
+	if d.Recorder != nil && e.match {
+		d.Recorder.RecordNotification(userID, e.listing, status)
+	}
+}
+
+func statusOf(err error) Status {
+	if err != nil {
+		return StatusFailed
+	}
+	return StatusSent
+}
+
+// Run flushes queued listings every minute until ctx is cancelled, then drains the
+// queues so that a restart during quiet hours loses nothing
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), drainTimeout)
+			defer cancel()
+			d.Drain(drainCtx)
+			return
+		case <-ticker.C:
+			d.Flush(ctx)
+		}
+	}
+}
+
+// Flush delivers the queued listings of every user whose quiet hours are over. Listings
+// that could not be delivered stay queued for the next flush.
+func (d *Dispatcher) Flush(ctx context.Context) {
+	d.flush(ctx, false)
+}
+
+// Drain delivers all queued listings, also of users still in quiet hours. The batches are
+// silent like every batch, listings that could not be delivered stay queued.
+func (d *Dispatcher) Drain(ctx context.Context) {
+	d.flush(ctx, true)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for userID, q := range d.queues {
+		log.Printf("[notify] %d queued listings of user %q could not be delivered", len(q.entries), userID)
+	}
+}
+
+func (d *Dispatcher) flush(ctx context.Context, all bool) {
+	now := d.now()
+
+	d.mu.Lock()
+	var due []*queue
+	for userID, q := range d.queues {
+		if all || !d.inQuietHours(&q.user, now) {
+			due = append(due, q)
+			delete(d.queues, userID)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, q := range due {
+		sent, err := d.sendBatch(ctx, q)
+		if err != nil {
+			log.Printf("[notify] failed to deliver queued listings to user %q: %v", q.user.UserID, err)
+			d.requeue(q.user, q.entries[sent:])
+		}
+	}
+}
+
+// Pending returns the number of listings queued for a user
+func (d *Dispatcher) Pending(userID string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if q, ok := d.queues[userID]; ok {
+		return len(q.entries)
+	}
+	return 0
+}
+
+// sendBatch delivers the queued listings silently in chunks and returns how many were sent
+// before a chunk failed
+func (d *Dispatcher) sendBatch(ctx context.Context, q *queue) (int, error) {
+	total := len(q.entries)
+	for start := 0; start < total; start += maxBatchSize {
+		end := min(start+maxBatchSize, total)
+
+		header := fmt.Sprintf("%d listings during quiet hours", total)
+		if total > maxBatchSize {
+			header = fmt.Sprintf("%s (%d-%d)", header, start+1, end)
+		}
+
+		infos := make([]*telegram.TelegramInfo, 0, end-start)
+		for _, e := range q.entries[start:end] {
+			infos = append(infos, e.info)
+		}
+		opts := telegram.MessageOptions{ChatID: q.user.ChatID, DisableNotification: true}
+		err := d.sender.SendMessageWithOptions(ctx, telegram.BuildBatchHTML(header, infos), opts)
+		for _, e := range q.entries[start:end] {
+			d.record(q.user.UserID, e, statusOf(err))
+		}
+		if err != nil {
+			return start, err
+		}
+	}
+	return total, nil
+}
+
+func (d *Dispatcher) enqueue(user *users.UserConfig, e entry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	q, ok := d.queues[user.UserID]
+	if !ok {
+		q = &queue{user: *user}
+		d.queues[user.UserID] = q
+	}
+	q.entries = append(q.entries, e)
+}
+
+// requeue puts undelivered entries back in front of those queued since the flush started
+func (d *Dispatcher) requeue(user users.UserConfig, entries []entry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	q, ok := d.queues[user.UserID]
+	if !ok {
+		q = &queue{user: user}
+		d.queues[user.UserID] = q
+	}
+	q.entries = append(append([]entry(nil), entries...), q.entries...)
+}
+
+func (d *Dispatcher) inQuietHours(user *users.UserConfig, t time.Time) bool {
+	return user.QuietHours != nil && user.QuietHours.Contains(t.In(d.location))
+}
+
+func isUrgent(user *users.UserConfig, listing common.Listing) bool {
+	if user.UrgentMaxPrice == 0 {
+		return false
+	}
+	price, err := listing.PriceValue()
+	return err == nil && int(price) <= user.UrgentMaxPrice
+}
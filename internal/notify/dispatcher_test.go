@@ -0,0 +1,393 @@
+package notify
+
+import (
+	"apartmenthunter/internal/geo"
+	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/telegram"
+	"apartmenthunter/internal/users"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sentMessage struct {
+	html string
+	opts telegram.MessageOptions
+}
+
+type stubSender struct {
+	sent []sentMessage
+	err  error
+}
+
+func (s *stubSender) SendMessageWithOptions(_ context.Context, htmlMessage string, opts telegram.MessageOptions) error {
+	s.sent = append(s.sent, sentMessage{htmlMessage, opts})
+	return s.err
+}
+
+func newTestDispatcher(sender Sender, now *time.Time) *Dispatcher {
+	d := NewDispatcher(sender, time.UTC)
+	d.now = func() time.Time { return *now }
+	return d
+}
+
+func quietUser() *users.UserConfig {
+	return &users.UserConfig{
+		UserID:         "user",
+		ChatID:         "chat",
+		QuietHours:     &users.QuietHours{Start: "23:00", End: "07:00"},
+		UrgentMaxPrice: 700,
+	}
+}
+
+func TestDispatcher_Notify(t *testing.T) {
+	tests := []struct {
+		name        string
+		now         time.Time
+		user        *users.UserConfig
+		listing     common.Listing
+		wantSent    bool
+		wantPending int
+	}{
+		{
+			name:     "outside quiet hours",
+			now:      time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+			user:     quietUser(),
+			listing:  common.Listing{ID: "1", Price: "900"},
+			wantSent: true,
+		},
+		{
+			name:        "inside quiet hours",
+			now:         time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC),
+			user:        quietUser(),
+			listing:     common.Listing{ID: "1", Price: "900"},
+			wantSent:    false,
+			wantPending: 1,
+		},
+		{
+			name:     "urgent listing inside quiet hours",
+			now:      time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC),
+			user:     quietUser(),
+			listing:  common.Listing{ID: "1", Price: "650"},
+			wantSent: true,
+		},
+		{
+			name:        "unparseable price is not urgent",
+			now:         time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC),
+			user:        quietUser(),
+			listing:     common.Listing{ID: "1", Price: "auf Anfrage"},
+			wantSent:    false,
+			wantPending: 1,
+		},
+		{
+			name:     "no quiet hours configured",
+			now:      time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC),
+			user:     &users.UserConfig{UserID: "user"},
+			listing:  common.Listing{ID: "1", Price: "900"},
+			wantSent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &stubSender{}
+			d := newTestDispatcher(sender, &tt.now)
+
+			if err := d.Notify(context.Background(), tt.user, tt.listing); err != nil {
+				t.Fatalf("Notify() unexpected error: %v", err)
+			}
+
+			if gotSent := len(sender.sent) == 1; gotSent != tt.wantSent {
+				t.Errorf("sent = %v, want %v", gotSent, tt.wantSent)
+			}
+			if tt.wantSent {
+				if sender.sent[0].opts.ChatID != tt.user.ChatID {
+					t.Errorf("ChatID = %q, want %q", sender.sent[0].opts.ChatID, tt.user.ChatID)
+				}
+				if sender.sent[0].opts.DisableNotification {
+					t.Error("immediate notifications should not be silent")
+				}
+			}
+			if got := d.Pending(tt.user.UserID); got != tt.wantPending {
+				t.Errorf("Pending() = %d, want %d", got, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Flush(t *testing.T) {
+	now := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	sender := &stubSender{}
+	d := newTestDispatcher(sender, &now)
+	user := quietUser()
+
+	_ = d.Notify(context.Background(), user, common.Listing{ID: "1", Price: "900", Address: "First"})
+	_ = d.Notify(context.Background(), user, common.Listing{ID: "2", Price: "950", Address: "Second"})
+
+	d.Flush(context.Background())
+	if len(sender.sent) != 0 {
+		t.Fatalf("Flush() during quiet hours sent %d messages, want 0", len(sender.sent))
+	}
+
+	now = time.Date(2026, 10, 19, 7, 1, 0, 0, time.UTC)
+	d.Flush(context.Background())
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("Flush() after quiet hours sent %d messages, want 1", len(sender.sent))
+	}
+	msg := sender.sent[0]
+	if !strings.HasPrefix(msg.html, "<b>2 listings during quiet hours</b>") {
+		t.Errorf("batch message header missing, got %s", msg.html)
+	}
+	if !strings.Contains(msg.html, "First") || !strings.Contains(msg.html, "Second") {
+		t.Errorf("batch message should contain both listings, got %s", msg.html)
+	}
+	if msg.opts.ChatID != "chat" {
+		t.Errorf("ChatID = %q, want chat", msg.opts.ChatID)
+	}
+	if !msg.opts.DisableNotification {
+		t.Error("batched listings should be delivered silently")
+	}
+	if d.Pending(user.UserID) != 0 {
+		t.Errorf("queue should be empty after flush, got %d", d.Pending(user.UserID))
+	}
+}
+
+func TestDispatcher_FlushSplitsLargeBatches(t *testing.T) {
+	now := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	sender := &stubSender{}
+	d := newTestDispatcher(sender, &now)
+	user := quietUser()
+
+	for i := 0; i < maxBatchSize+3; i++ {
+		_ = d.Notify(context.Background(), user, common.Listing{ID: "id", Price: "900"})
+	}
+
+	now = time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	d.Flush(context.Background())
+
+	if len(sender.sent) != 2 {
+		t.Fatalf("Flush() sent %d messages, want 2", len(sender.sent))
+	}
+	if !strings.Contains(sender.sent[0].html, "(1-10)") || !strings.Contains(sender.sent[1].html, "(11-13)") {
+		t.Errorf("batch headers should contain ranges, got %q and %q", sender.sent[0].html[:60], sender.sent[1].html[:60])
+	}
+}
+
+func TestDispatcher_FlushError(t *testing.T) {
+	now := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	sender := &stubSender{err: errors.New("telegram down")}
+	d := newTestDispatcher(sender, &now)
+
+	_ = d.Notify(context.Background(), quietUser(), common.Listing{ID: "1", Price: "900"})
+	now = time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	d.Flush(context.Background())
+
+	if len(sender.sent) != 1 {
+		t.Errorf("Flush() should attempt delivery once, got %d attempts", len(sender.sent))
+	}
+	if d.Pending("user") != 1 {
+		t.Errorf("undelivered listing should stay queued, got %d pending", d.Pending("user"))
+	}
+
+	sender.err = nil
+	d.Flush(context.Background())
+	if len(sender.sent) != 2 || d.Pending("user") != 0 {
+		t.Errorf("next Flush() should deliver the listing, got %d attempts and %d pending", len(sender.sent), d.Pending("user"))
+	}
+}
+
+func TestDispatcher_RunDrainsOnShutdown(t *testing.T) {
+	now := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	sender := &stubSender{}
+	d := newTestDispatcher(sender, &now)
+	user := quietUser()
+
+	_ = d.Notify(context.Background(), user, common.Listing{ID: "1", Price: "900", Address: "First"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.Run(ctx)
+
+	if len(sender.sent) != 1 || !strings.Contains(sender.sent[0].html, "First") {
+		t.Fatalf("Run() sent %v on shutdown, want the queued listing", sender.sent)
+	}
+	if !sender.sent[0].opts.DisableNotification {
+		t.Error("listings drained during quiet hours should be delivered silently")
+	}
+	if d.Pending(user.UserID) != 0 {
+		t.Errorf("Pending() = %d after drain, want 0", d.Pending(user.UserID))
+	}
+}
+
+// failingSender fails from the given call on
+type failingSender struct {
+	stubSender
+	failFrom int
+}
+
+func (s *failingSender) SendMessageWithOptions(ctx context.Context, htmlMessage string, opts telegram.MessageOptions) error {
+	s.sent = append(s.sent, sentMessage{htmlMessage, opts})
+	if len(s.sent) >= s.failFrom {
+		return errors.New("telegram down")
+	}
+	return nil
+}
+
+func TestDispatcher_FlushKeepsUnsentChunks(t *testing.T) {
+	now := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	sender := &failingSender{failFrom: 2}
+	d := newTestDispatcher(sender, &now)
+	user := quietUser()
+
+	for i := 0; i < maxBatchSize+3; i++ {
+		_ = d.Notify(context.Background(), user, common.Listing{ID: "id", Price: "900"})
+	}
+	now = time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	d.Flush(context.Background())
+
+	if len(sender.sent) != 2 {
+		t.Fatalf("Flush() made %d attempts, want 2", len(sender.sent))
+	}
+	if d.Pending(user.UserID) != 3 {
+		t.Errorf("Pending() = %d, want the 3 listings of the failed chunk", d.Pending(user.UserID))
+	}
+	_ = d.Notify(context.Background(), user, common.Listing{ID: "new", Price: "900"})
+	if d.Pending(user.UserID) != 3 {
+		t.Errorf("listing after quiet hours should not be queued, got %d pending", d.Pending(user.UserID))
+	}
+}
+
+func TestDispatcher_NotifyNearMatch(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	sender := &stubSender{}
+	d := newTestDispatcher(sender, &now)
+	misses := []common.Miss{{Criterion: "rent", Detail: "rent 1010 € is 10 € (1.0%) over your max of 1000 €"}}
+
+	if err := d.NotifyNearMatch(context.Background(), quietUser(), common.Listing{ID: "1", Price: "1010", Company: "WBM"}, misses); err != nil {
+		t.Fatalf("NotifyNearMatch() unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sender.sent))
+	}
+	if !strings.HasPrefix(sender.sent[0].html, "<b>Near match: WBM Listing</b>") {
+		t.Errorf("near match should be labelled, got %s", sender.sent[0].html)
+	}
+	if !strings.Contains(sender.sent[0].html, misses[0].Detail) {
+		t.Errorf("near match should explain the miss, got %s", sender.sent[0].html)
+	}
+
+	// cheap near matches are still queued during quiet hours
+	now = time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	_ = d.NotifyNearMatch(context.Background(), quietUser(), common.Listing{ID: "2", Price: "500"}, misses)
+	if d.Pending("user") != 1 {
+		t.Errorf("Pending() = %d, want near match to be queued", d.Pending("user"))
+	}
+}
+
+func TestDispatcher_NotifyIncludesScore(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	sender := &stubSender{}
+	d := newTestDispatcher(sender, &now)
+
+	user := &users.UserConfig{
+		UserID:      "user",
+		Preferences: &users.Preferences{Weights: users.Weights{Balcony: 1}},
+	}
+	_ = d.Notify(context.Background(), user, common.Listing{ID: "1", Balcony: true})
+	_ = d.Notify(context.Background(), &users.UserConfig{UserID: "plain"}, common.Listing{ID: "2"})
+
+	if !strings.Contains(sender.sent[0].html, "<b>Score:</b> 100/100") {
+		t.Errorf("notification should contain the score, got %s", sender.sent[0].html)
+	}
+	if strings.Contains(sender.sent[1].html, "Score") {
+		t.Errorf("notification without preferences should not contain a score, got %s", sender.sent[1].html)
+	}
+}
+
+// TestDispatcher_NotifyIncludesCommutes tests that estimated commutes are shown
+func TestDispatcher_NotifyIncludesCommutes(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	sender := &stubSender{}
+	d := newTestDispatcher(sender, &now)
+
+	work := geo.Point{Lat: 52.5125, Lon: 13.39}
+	user := &users.UserConfig{
+		UserID:   "user",
+		Commutes: []users.Commute{{Name: "work", Destination: work, MaxMinutes: 40}},
+	}
+	listing := common.Listing{ID: "1", Commutes: map[geo.Point]int{work: 23}}
+	_ = d.Notify(context.Background(), user, listing)
+
+	if !strings.Contains(sender.sent[0].html, "<i>23 min to work by public transport</i>") {
+		t.Errorf("notification should contain the commute, got %s", sender.sent[0].html)
+	}
+}
+
+func TestDispatcher_NotifyUpdate(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	sender := &stubSender{}
+	d := newTestDispatcher(sender, &now)
+
+	listing := common.Listing{ID: "1", Price: "980", Company: "WBM"}
+	if err := d.NotifyUpdate(context.Background(), quietUser(), listing, "Price change", "rent reduced from 1050 to 980 €"); err != nil {
+		t.Fatalf("NotifyUpdate() unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sender.sent))
+	}
+	if !strings.HasPrefix(sender.sent[0].html, "<b>Price change: WBM Listing</b>") {
+		t.Errorf("update should be labelled, got %s", sender.sent[0].html)
+	}
+	if !strings.Contains(sender.sent[0].html, "rent reduced from 1050 to 980 €") {
+		t.Errorf("update should describe the change, got %s", sender.sent[0].html)
+	}
+}
+
+type recordedNotification struct {
+	userID    string
+	listingID string
+	status    Status
+}
+
+type stubRecorder struct {
+	recorded []recordedNotification
+}
+
+func (r *stubRecorder) RecordNotification(userID string, listing common.Listing, status Status) {
+	r.recorded = append(r.recorded, recordedNotification{userID, listing.ID, status})
+}
+
+func TestDispatcher_Recorder(t *testing.T) {
+	now := time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+	sender := &stubSender{}
+	recorder := &stubRecorder{}
+	d := newTestDispatcher(sender, &now)
+	d.Recorder = recorder
+	ctx := context.Background()
+
+	_ = d.Notify(ctx, quietUser(), common.Listing{ID: "queued", Price: "900"})
+	_ = d.Notify(ctx, quietUser(), common.Listing{ID: "urgent", Price: "600"})
+	_ = d.NotifyNearMatch(ctx, quietUser(), common.Listing{ID: "near", Price: "900"}, nil)
+
+	now = time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	d.Flush(ctx)
+	sender.err = errors.New("telegram down")
+	_ = d.Notify(ctx, quietUser(), common.Listing{ID: "failed", Price: "900"})
+
+	want := []recordedNotification{
+		{"user", "queued", StatusQueued},
+		{"user", "urgent", StatusSent},
+		{"user", "queued", StatusSent},
+		{"user", "failed", StatusFailed},
+	}
+	if len(recorder.recorded) != len(want) {
+		t.Fatalf("recorded %v, want %v", recorder.recorded, want)
+	}
+	for i := range want {
+		if recorder.recorded[i] != want[i] {
+			t.Errorf("recorded[%d] = %v, want %v", i, recorder.recorded[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package common
+
+import "fmt"
+
+// PriceChangeNote describes how the rent changed between two versions of a listing,
+// empty if either rent cannot be read or it did not change
+func PriceChangeNote(previous, current Listing) string {
+	before, err := previous.PriceValue()
+	if err != nil {
+		return ""
+	}
+	after, err := current.PriceValue()
+	if err != nil || after == before {
+		return ""
+	}
+
+	direction := "reduced"
+	if after > before {
+		direction = "increased"
+	}
+	return fmt.Sprintf("rent %s from %s to %s €", direction, formatAmount(before), formatAmount(after))
+}
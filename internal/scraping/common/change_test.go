@@ -0,0 +1,28 @@
+package common
+
+import "testing"
+
+// TestPriceChangeNote tests the description of rent changes
+func TestPriceChangeNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{"reduced", "1050", "980", "rent reduced from 1050 to 980 €"},
+		{"increased", "980", "1.050,00", "rent increased from 980 to 1050 €"},
+		{"decimals", "799.50", "750", "rent reduced from 799.5 to 750 €"},
+		{"unchanged", "800", "800,00", ""},
+		{"unparseable", "auf Anfrage", "800", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := PriceChangeNote(Listing{Price: tt.before}, Listing{Price: tt.after})
+			if note != tt.expected {
+				t.Errorf("PriceChangeNote() = %q, want %q", note, tt.expected)
+			}
+		})
+	}
+}
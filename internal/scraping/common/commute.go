@@ -0,0 +1,43 @@
+package common
+
+import (
+	"apartmenthunter/internal/transit"
+	"apartmenthunter/internal/users"
+	"fmt"
+)
+
+// EstimateCommutes computes the public transport minutes from a geocoded listing to
+// every destination the estimator knows. The estimator may be nil.
+func (l *Listing) EstimateCommutes(estimator *transit.Estimator) {
+	point, ok := l.Point()
+	if estimator == nil || !ok {
+		return
+	}
+	l.Commutes = estimator.Estimate(point)
+}
+
+// matchesCommutes checks the user's maximum commute times. A listing without an
+// estimate for a destination (not geocoded or unreachable) fails that commute.
+func (l Listing) matchesCommutes(commutes []users.Commute) bool {
+	for _, commute := range commutes {
+		if commute.MaxMinutes <= 0 {
+			continue
+		}
+		minutes, ok := l.Commutes[commute.Destination]
+		if !ok || minutes > commute.MaxMinutes {
+			return false
+		}
+	}
+	return true
+}
+
+// CommuteNotes describes the estimated commutes to the user's destinations
+func (l Listing) CommuteNotes(userConfig *users.UserConfig) []string {
+	var notes []string
+	for _, commute := range userConfig.Commutes {
+		if minutes, ok := l.Commutes[commute.Destination]; ok {
+			notes = append(notes, fmt.Sprintf("%d min to %s by public transport", minutes, commute.Name))
+		}
+	}
+	return notes
+}
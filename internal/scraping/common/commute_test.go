@@ -0,0 +1,81 @@
+package common
+
+import (
+	"apartmenthunter/internal/geo"
+	"apartmenthunter/internal/users"
+	"testing"
+)
+
+// TestListing_MatchesCommutes tests the maximum commute time filter
+func TestListing_MatchesCommutes(t *testing.T) {
+	work := geo.Point{Lat: 52.5125, Lon: 13.39}
+	school := geo.Point{Lat: 52.487, Lon: 13.4245}
+
+	tests := []struct {
+		name     string
+		commutes map[geo.Point]int
+		user     users.UserConfig
+		expected bool
+	}{
+		{
+			name:     "no commute configured",
+			user:     users.UserConfig{},
+			expected: true,
+		},
+		{
+			name:     "within max minutes",
+			commutes: map[geo.Point]int{work: 35},
+			user:     users.UserConfig{Commutes: []users.Commute{{Name: "work", Destination: work, MaxMinutes: 40}}},
+			expected: true,
+		},
+		{
+			name:     "too long",
+			commutes: map[geo.Point]int{work: 45},
+			user:     users.UserConfig{Commutes: []users.Commute{{Name: "work", Destination: work, MaxMinutes: 40}}},
+			expected: false,
+		},
+		{
+			name:     "no estimate",
+			user:     users.UserConfig{Commutes: []users.Commute{{Name: "work", Destination: work, MaxMinutes: 40}}},
+			expected: false,
+		},
+		{
+			name:     "commute without limit",
+			user:     users.UserConfig{Commutes: []users.Commute{{Name: "work", Destination: work}}},
+			expected: true,
+		},
+		{
+			name:     "every destination has to be within its limit",
+			commutes: map[geo.Point]int{work: 35, school: 25},
+			user: users.UserConfig{Commutes: []users.Commute{
+				{Name: "work", Destination: work, MaxMinutes: 40},
+				{Name: "school", Destination: school, MaxMinutes: 20},
+			}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listing := Listing{Commutes: tt.commutes}
+			if result := listing.MatchUserConfig(&tt.user); result != tt.expected {
+				t.Errorf("MatchUserConfig() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestListing_CommuteNotes tests the commute descriptions for notifications
+func TestListing_CommuteNotes(t *testing.T) {
+	work := geo.Point{Lat: 52.5125, Lon: 13.39}
+	school := geo.Point{Lat: 52.487, Lon: 13.4245}
+	user := &users.UserConfig{Commutes: []users.Commute{
+		{Name: "work", Destination: work},
+		{Name: "school", Destination: school},
+	}}
+
+	notes := Listing{Commutes: map[geo.Point]int{work: 23}}.CommuteNotes(user)
+	if len(notes) != 1 || notes[0] != "23 min to work by public transport" {
+		t.Errorf("CommuteNotes() = %v", notes)
+	}
+}
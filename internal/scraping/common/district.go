@@ -0,0 +1,63 @@
+package common
+
+import (
+	"apartmenthunter/internal/districts"
+	"apartmenthunter/internal/geo"
+)
+
+// EnrichLocation fills in the zip code, district and coordinates of a listing from its
+// address where the scraper did not set them. A district set by the scraper (e.g.
+// Degewo's neighbourhood) is normalized to its dataset spelling. The geocoder may be nil.
+func (l *Listing) EnrichLocation(geocoder *geo.Geocoder) {
+	if geocoder != nil && l.Lat == 0 && l.Lon == 0 {
+		if point, ok := geocoder.Geocode(l.Address); ok {
+			l.Lat, l.Lon = point.Lat, point.Lon
+		}
+	}
+
+	if l.ZipCode == "" {
+		if zip, ok := ExtractZIP(l.Address); ok {
+			l.ZipCode = zip
+		}
+	}
+
+	if l.District != "" {
+		if area, ok := districts.FromText(l.District); ok {
+			l.District = area.Name()
+		}
+		return
+	}
+	if areas := districts.ForZip(l.ZipCode); len(areas) > 0 {
+		l.District = areas[0].Name()
+	} else if area, ok := districts.FromText(l.Address); ok {
+		l.District = area.Name()
+	}
+}
+
+// areas returns the known areas of the listing: its district and every area its zip code covers
+func (l Listing) areas() []districts.Area {
+	areas := districts.ForZip(l.ZipCode)
+	if area, ok := districts.Resolve(l.District); ok {
+		areas = append([]districts.Area{area}, areas...)
+	}
+	return areas
+}
+
+// InDistrict reports whether the listing lies in the named Bezirk or Ortsteil
+func (l Listing) InDistrict(name string) bool {
+	for _, area := range l.areas() {
+		if area.In(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l Listing) matchesAnyDistrict(names []string) bool {
+	for _, name := range names {
+		if l.InDistrict(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package common
+
+import (
+	"apartmenthunter/internal/users"
+	"testing"
+)
+
+// TestListing_EnrichLocation tests deriving zip code and district from the listing
+func TestListing_EnrichLocation(t *testing.T) {
+	tests := []struct {
+		name         string
+		listing      Listing
+		wantZipCode  string
+		wantDistrict string
+	}{
+		{
+			name:         "district from zip code",
+			listing:      Listing{ZipCode: "10247"},
+			wantZipCode:  "10247",
+			wantDistrict: "Friedrichshain",
+		},
+		{
+			name:         "zip code and district from address",
+			listing:      Listing{Address: "Weserstraße 5, 12047 Berlin"},
+			wantZipCode:  "12047",
+			wantDistrict: "Neukölln",
+		},
+		{
+			name:         "district from address text",
+			listing:      Listing{Address: "Oranienstraße 1, Kreuzberg, Berlin"},
+			wantDistrict: "Kreuzberg",
+		},
+		{
+			name:         "scraper district is normalized",
+			listing:      Listing{Address: "Allee der Kosmonauten 1, Marzahn-Hellersdorf - Marzahn, Berlin", District: "Marzahn-Hellersdorf - Marzahn"},
+			wantDistrict: "Marzahn",
+		},
+		{
+			name:         "unknown scraper district is kept",
+			listing:      Listing{District: "Irgendwo"},
+			wantDistrict: "Irgendwo",
+		},
+		{
+			name:    "nothing to derive from",
+			listing: Listing{Address: "Musterstraße 1, Berlin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := tt.listing
+			l.EnrichLocation(nil)
+			if l.ZipCode != tt.wantZipCode || l.District != tt.wantDistrict {
+				t.Errorf("EnrichLocation() = %q, %q, want %q, %q", l.ZipCode, l.District, tt.wantZipCode, tt.wantDistrict)
+			}
+		})
+	}
+}
+
+// TestListing_MatchesLocation tests filtering by zip codes and district names
+func TestListing_MatchesLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		listing  Listing
+		user     users.UserConfig
+		expected bool
+	}{
+		{
+			name:     "no location restriction",
+			listing:  Listing{ZipCode: "13581"},
+			user:     users.UserConfig{},
+			expected: true,
+		},
+		{
+			name:     "ortsteil by zip code",
+			listing:  Listing{ZipCode: "10247"},
+			user:     users.UserConfig{Districts: []string{"Friedrichshain"}},
+			expected: true,
+		},
+		{
+			name:     "bezirk by zip code",
+			listing:  Listing{ZipCode: "10963"},
+			user:     users.UserConfig{Districts: []string{"Friedrichshain-Kreuzberg"}},
+			expected: true,
+		},
+		{
+			name:     "district without zip code",
+			listing:  Listing{District: "Neukölln"},
+			user:     users.UserConfig{Districts: []string{"neukoelln"}},
+			expected: true,
+		},
+		{
+			name:     "secondary area of zip code",
+			listing:  Listing{ZipCode: "10119"},
+			user:     users.UserConfig{Districts: []string{"Prenzlauer Berg"}},
+			expected: true,
+		},
+		{
+			name:     "other district",
+			listing:  Listing{ZipCode: "13581"},
+			user:     users.UserConfig{Districts: []string{"Neukölln", "Kreuzberg"}},
+			expected: false,
+		},
+		{
+			name:     "zip code matches although district does not",
+			listing:  Listing{ZipCode: "13581"},
+			user:     users.UserConfig{ZipCodes: []string{"13581"}, Districts: []string{"Neukölln"}},
+			expected: true,
+		},
+		{
+			name:     "district matches although zip code does not",
+			listing:  Listing{ZipCode: "12047"},
+			user:     users.UserConfig{ZipCodes: []string{"13581"}, Districts: []string{"Neukölln"}},
+			expected: true,
+		},
+		{
+			name:     "unknown location with district filter",
+			listing:  Listing{},
+			user:     users.UserConfig{Districts: []string{"Neukölln"}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.listing.MatchUserConfig(&tt.user); result != tt.expected {
+				t.Errorf("MatchUserConfig() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -1,50 +1,14 @@
 package common
 
 import (
-	"apartmenthunter/internal/users"
+	"apartmenthunter/internal/wbs"
 	"regexp"
 	"strings"
 )
 
-func SendNotification(listing Listing, config *users.FilterConfig) bool {
-	if listing.MatchUserConfig(&config.Users[0]) {
-		return true
-	}
-	return false
-}
-
+// FilterWBSString reports whether the title asks for any WBS, see wbs.Parse for the details
 func FilterWBSString(title string) bool {
-	t := strings.ToLower(title)
-
-	// “Without WBS” phrasings
-	neg := []string{
-		"ohne wbs",
-		"ohne wohnberechtigungsschein",
-		"wbs-frei", "wbs frei",
-		"o. wbs",
-		"kein wbs", "keine wbs",
-		"wbs nicht erforderlich",
-		"ohne wohnberechtigung",
-	}
-	for _, n := range neg {
-		if strings.Contains(t, n) {
-			return false
-		}
-	}
-
-	pos := []string{
-		"wohnberechtigungsschein",
-		"nur mit wbs", "mit wbs",
-		"wbs pflicht", "wbs erforderlich",
-		"wbs",
-	}
-	for _, p := range pos {
-		if strings.Contains(t, p) {
-			return true
-		}
-	}
-
-	return false //if no wbs then just return false
+	return wbs.Parse(title).Required
 }
 
 // ExtractZIP finds the first German ZIP code (5 digits) in addr.
@@ -56,3 +20,39 @@ func ExtractZIP(addr string) (string, bool) {
 	}
 	return "", false
 }
+
+// ExtractRooms finds the number of rooms in texts like "2 Zimmer" or "2,5-Zimmer-Wohnung".
+func ExtractRooms(text string) (string, bool) {
+	roomsRe := regexp.MustCompile(`(?i)(\d+(?:[.,]5)?)\s*-?\s*(?:zimmer|zi\.|räume)`)
+	if matches := roomsRe.FindStringSubmatch(text); len(matches) == 2 {
+		return strings.Replace(matches[1], ",", ".", 1), true
+	}
+	return "", false
+}
+
+// ExtractFloor finds the floor in texts like "im 3. OG", "2. Etage" or "Erdgeschoss".
+// The ground floor is returned as "0".
+func ExtractFloor(text string) (string, bool) {
+	t := strings.ToLower(text)
+	groundRe := regexp.MustCompile(`\b(?:eg|erdgeschoss\w*|hochparterre\w*)\b`)
+	if groundRe.MatchString(t) {
+		return "0", true
+	}
+
+	floorRe := regexp.MustCompile(`(\d{1,2})\.\s*(?:og\b|obergeschoss|etage|stock)`)
+	if matches := floorRe.FindStringSubmatch(t); len(matches) == 2 {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// HasBalcony reports whether a text mentions a balcony, loggia or terrace.
+func HasBalcony(text string) bool {
+	t := strings.ToLower(text)
+	for _, keyword := range []string{"balkon", "loggia", "terrasse"} {
+		if strings.Contains(t, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -418,3 +418,80 @@ func TestExtractZIP_BerlinSpecific(t *testing.T) {
 		})
 	}
 }
+
+// TestExtractRooms tests room count extraction
+func TestExtractRooms(t *testing.T) {
+	tests := []struct {
+		input      string
+		expected   string
+		expectedOk bool
+	}{
+		{"2 Zimmer", "2", true},
+		{"3-Zimmer-Wohnung mit Balkon", "3", true},
+		{"Schöne 2,5 Zimmer in Neukölln", "2.5", true},
+		{"1 Zi. Apartment", "1", true},
+		{"4 Räume, 90 m²", "4", true},
+		{"Wohnung 55,30 m²", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, ok := ExtractRooms(tt.input)
+			if result != tt.expected || ok != tt.expectedOk {
+				t.Errorf("ExtractRooms(%q) = (%q, %v), want (%q, %v)", tt.input, result, ok, tt.expected, tt.expectedOk)
+			}
+		})
+	}
+}
+
+// TestExtractFloor tests floor extraction
+func TestExtractFloor(t *testing.T) {
+	tests := []struct {
+		input      string
+		expected   string
+		expectedOk bool
+	}{
+		{"Wohnung im 3. OG", "3", true},
+		{"2. Etage mit Aufzug", "2", true},
+		{"1.OG links", "1", true},
+		{"Erdgeschosswohnung", "0", true},
+		{"Wohnung im Erdgeschoss", "0", true},
+		{"EG rechts", "0", true},
+		{"Hochparterre mit Garten", "0", true},
+		{"Dachgeschoss", "", false},
+		{"2 Zimmer, 55 m²", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, ok := ExtractFloor(tt.input)
+			if result != tt.expected || ok != tt.expectedOk {
+				t.Errorf("ExtractFloor(%q) = (%q, %v), want (%q, %v)", tt.input, result, ok, tt.expected, tt.expectedOk)
+			}
+		})
+	}
+}
+
+// TestHasBalcony tests balcony detection
+func TestHasBalcony(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"2-Zimmer mit Balkon", true},
+		{"Wohnung mit großer Loggia", true},
+		{"Dachterrasse inklusive", true},
+		{"BALKON Richtung Süden", true},
+		{"Ruhige Wohnung im Hinterhof", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := HasBalcony(tt.input); result != tt.expected {
+				t.Errorf("HasBalcony(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"apartmenthunter/internal/geo"
+	"apartmenthunter/internal/users"
+)
+
+// Point returns the coordinates of the listing, false if it was not geocoded
+func (l Listing) Point() (geo.Point, bool) {
+	if l.Lat == 0 && l.Lon == 0 {
+		return geo.Point{}, false
+	}
+	return geo.Point{Lat: l.Lat, Lon: l.Lon}, true
+}
+
+// matchesGeo applies the user's radius and area filters. A listing without coordinates
+// fails them, as its exact position is unknown.
+func (l Listing) matchesGeo(userConfig *users.UserConfig) bool {
+	if userConfig.Radius == nil && userConfig.Area == nil {
+		return true // No restriction
+	}
+
+	point, ok := l.Point()
+	if !ok {
+		return false
+	}
+	if r := userConfig.Radius; r != nil && geo.DistanceKm(r.Center, point) > r.Km {
+		return false
+	}
+	if userConfig.Area != nil && !userConfig.Area.Contains(point) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"apartmenthunter/internal/geo"
+	"apartmenthunter/internal/users"
+	"testing"
+)
+
+// TestListing_MatchesGeo tests the radius and polygon filters
+func TestListing_MatchesGeo(t *testing.T) {
+	school := geo.Point{Lat: 52.4870, Lon: 13.4245}
+	area, err := geo.ParsePolygon([]byte(`{"type":"Polygon","coordinates":` +
+		`[[[13.40,52.48],[13.45,52.48],[13.45,52.50],[13.40,52.50],[13.40,52.48]]]}`))
+	if err != nil {
+		t.Fatalf("ParsePolygon() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		listing  Listing
+		user     users.UserConfig
+		expected bool
+	}{
+		{
+			name:     "no geographic filter",
+			listing:  Listing{},
+			user:     users.UserConfig{},
+			expected: true,
+		},
+		{
+			name:     "within radius",
+			listing:  Listing{Lat: 52.4880, Lon: 13.4310},
+			user:     users.UserConfig{Radius: &users.Radius{Center: school, Km: 1}},
+			expected: true,
+		},
+		{
+			name:     "outside radius",
+			listing:  Listing{Lat: 52.5219, Lon: 13.4132},
+			user:     users.UserConfig{Radius: &users.Radius{Center: school, Km: 1}},
+			expected: false,
+		},
+		{
+			name:     "not geocoded",
+			listing:  Listing{Address: "Weserstraße 1, 12047 Berlin"},
+			user:     users.UserConfig{Radius: &users.Radius{Center: school, Km: 1}},
+			expected: false,
+		},
+		{
+			name:     "inside area",
+			listing:  Listing{Lat: 52.49, Lon: 13.41},
+			user:     users.UserConfig{Area: area},
+			expected: true,
+		},
+		{
+			name:     "outside area",
+			listing:  Listing{Lat: 52.52, Lon: 13.41},
+			user:     users.UserConfig{Area: area},
+			expected: false,
+		},
+		{
+			name:     "inside area but outside radius",
+			listing:  Listing{Lat: 52.49, Lon: 13.401},
+			user:     users.UserConfig{Area: area, Radius: &users.Radius{Center: school, Km: 1}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.listing.MatchUserConfig(&tt.user); result != tt.expected {
+				t.Errorf("MatchUserConfig() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestListing_EnrichLocation_Geocode tests geocoding listings from their address
+func TestListing_EnrichLocation_Geocode(t *testing.T) {
+	geocoder, err := geo.LoadGeocoder("../../geo/testdata/addresses.csv")
+	if err != nil {
+		t.Fatalf("LoadGeocoder() error = %v", err)
+	}
+
+	l := Listing{Address: "Weserstr. 5, 12047 Berlin"}
+	l.EnrichLocation(geocoder)
+	if l.Lat != 52.4870 || l.Lon != 13.4330 {
+		t.Errorf("EnrichLocation() coordinates = %f, %f, want 52.4870, 13.4330", l.Lat, l.Lon)
+	}
+
+	scraped := Listing{Address: "Weserstr. 5, 12047 Berlin", Lat: 52.1, Lon: 13.1}
+	scraped.EnrichLocation(geocoder)
+	if scraped.Lat != 52.1 || scraped.Lon != 13.1 {
+		t.Errorf("EnrichLocation() should keep scraped coordinates, got %f, %f", scraped.Lat, scraped.Lon)
+	}
+}
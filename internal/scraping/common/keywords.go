@@ -0,0 +1,97 @@
+package common
+
+import (
+	"apartmenthunter/internal/normalize"
+	"apartmenthunter/internal/users"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// compiled regex patterns by source, a nil entry marks an invalid pattern
+var patternCache sync.Map
+
+// NormalizeText lowercases a text, spells out German umlauts and collapses whitespace,
+// so that "Für Studierende" and "fuer  studierende" compare equal.
+func NormalizeText(text string) string {
+	return normalize.Text(text)
+}
+
+// matchesKeywords applies the user's include and exclude lists to the listing text.
+// If any include keyword or pattern is configured, at least one of them has to match.
+func (l Listing) matchesKeywords(userConfig *users.UserConfig) bool {
+	if len(userConfig.IncludeKeywords)+len(userConfig.IncludePatterns)+
+		len(userConfig.ExcludeKeywords)+len(userConfig.ExcludePatterns) == 0 {
+		return true
+	}
+
+	text := NormalizeText(l.Title + " " + l.Description)
+	if containsAnyKeyword(text, userConfig.ExcludeKeywords, true) || matchesAnyPattern(text, userConfig.ExcludePatterns) {
+		return false
+	}
+
+	if len(userConfig.IncludeKeywords)+len(userConfig.IncludePatterns) == 0 {
+		return true
+	}
+	return containsAnyKeyword(text, userConfig.IncludeKeywords, false) || matchesAnyPattern(text, userConfig.IncludePatterns)
+}
+
+// containsAnyKeyword reports whether a keyword starts a word, or with wordEnd also ends one,
+// so the exclude keyword "tausch" hits "Tauschwohnung" and "Wohnungstausch" but not
+// "ausgetauscht"
+func containsAnyKeyword(normalizedText string, keywords []string, wordEnd bool) bool {
+	for _, keyword := range keywords {
+		if k := NormalizeText(keyword); k != "" && containsKeyword(normalizedText, k, wordEnd) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsKeyword(text, keyword string, wordEnd bool) bool {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], keyword)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(keyword)
+		prev, _ := utf8.DecodeLastRuneInString(text[:start])
+		next, _ := utf8.DecodeRuneInString(text[end:])
+		if start == 0 || !isWordRune(prev) || wordEnd && (end == len(text) || !isWordRune(next)) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func matchesAnyPattern(normalizedText string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if re := compilePattern(pattern); re != nil && re.MatchString(normalizedText) {
+			return true
+		}
+	}
+	return false
+}
+
+// compilePattern compiles a user pattern case-insensitively with umlauts spelled out,
+// invalid patterns are logged once and never match
+func compilePattern(pattern string) *regexp.Regexp {
+	if cached, ok := patternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp)
+	}
+
+	re, err := regexp.Compile("(?i)" + normalize.Umlauts(pattern))
+	if err != nil {
+		log.Printf("invalid keyword pattern %q ignored: %v", pattern, err)
+		re = nil
+	}
+	patternCache.Store(pattern, re)
+	return re
+}
@@ -0,0 +1,180 @@
+package common
+
+import (
+	"apartmenthunter/internal/users"
+	"testing"
+)
+
+// TestNormalizeText tests lowercasing, umlaut replacement and whitespace handling
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Nur für Studierende", "nur fuer studierende"},
+		{"  GROẞE   Wohnung\n mit Balkon ", "grosse wohnung mit balkon"},
+		{"Seniorenwohnung Köpenick", "seniorenwohnung koepenick"},
+		{"Straße", "strasse"},
+		{"ÄÖÜ", "aeoeue"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := NormalizeText(tt.input); result != tt.expected {
+				t.Errorf("NormalizeText(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestListing_MatchesKeywords tests include and exclude filters on title and description
+func TestListing_MatchesKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		listing  Listing
+		user     users.UserConfig
+		expected bool
+	}{
+		{
+			name:     "no keyword filters",
+			listing:  Listing{Title: "Seniorenwohnung"},
+			user:     users.UserConfig{},
+			expected: true,
+		},
+		{
+			name:     "excluded keyword in title",
+			listing:  Listing{Title: "Seniorenwohnung in Neukölln"},
+			user:     users.UserConfig{ExcludeKeywords: []string{"Senioren"}},
+			expected: false,
+		},
+		{
+			name:     "excluded keyword in description",
+			listing:  Listing{Title: "2 Zimmer", Description: "Nur zum Tausch gegen eine größere Wohnung"},
+			user:     users.UserConfig{ExcludeKeywords: []string{"tausch"}},
+			expected: false,
+		},
+		{
+			name:     "excluded keyword at the start of a compound word",
+			listing:  Listing{Title: "Tauschwohnung gesucht"},
+			user:     users.UserConfig{ExcludeKeywords: []string{"Tausch"}},
+			expected: false,
+		},
+		{
+			name:     "excluded keyword at the end of a compound word",
+			listing:  Listing{Title: "Wohnungstausch Kreuzberg gegen Neukölln"},
+			user:     users.UserConfig{ExcludeKeywords: []string{"Tausch"}},
+			expected: false,
+		},
+		{
+			name:     "excluded keyword starting a compound word",
+			listing:  Listing{Title: "Seniorenwohnung mit Aufzug"},
+			user:     users.UserConfig{ExcludeKeywords: []string{"Senioren"}},
+			expected: false,
+		},
+		{
+			name:     "include keyword at the end of a compound word does not match",
+			listing:  Listing{Title: "Neubau-Dachgeschoss"},
+			user:     users.UserConfig{IncludeKeywords: []string{"geschoss"}},
+			expected: false,
+		},
+		{
+			name:     "excluded keyword inside a word does not match",
+			listing:  Listing{Title: "2 Zimmer", Description: "Fenster wurden 2023 ausgetauscht"},
+			user:     users.UserConfig{ExcludeKeywords: []string{"Tausch"}},
+			expected: true,
+		},
+		{
+			name:     "excluded keyword with umlaut spelled differently",
+			listing:  Listing{Title: "Wohnung nur fuer Studierende"},
+			user:     users.UserConfig{ExcludeKeywords: []string{"nur für Studierende"}},
+			expected: false,
+		},
+		{
+			name:     "excluded keyword with umlaut in listing",
+			listing:  Listing{Title: "Wohnung nur für  Studierende"},
+			user:     users.UserConfig{ExcludeKeywords: []string{"nur fuer studierende"}},
+			expected: false,
+		},
+		{
+			name:     "no excluded keyword present",
+			listing:  Listing{Title: "Schöne 2-Zimmer-Wohnung"},
+			user:     users.UserConfig{ExcludeKeywords: []string{"Senioren", "Gewerbe"}},
+			expected: true,
+		},
+		{
+			name:     "exclude pattern with word boundary",
+			listing:  Listing{Title: "Wohnung befristet auf 2 Jahre"},
+			user:     users.UserConfig{ExcludePatterns: []string{`\bbefristet\b`}},
+			expected: false,
+		},
+		{
+			name:     "exclude pattern does not hit unbefristet",
+			listing:  Listing{Title: "Wohnung unbefristet zu vermieten"},
+			user:     users.UserConfig{ExcludePatterns: []string{`\bbefristet\b`}},
+			expected: true,
+		},
+		{
+			name:     "include keyword present",
+			listing:  Listing{Title: "Altbau mit Balkon"},
+			user:     users.UserConfig{IncludeKeywords: []string{"altbau", "dielen"}},
+			expected: true,
+		},
+		{
+			name:     "include keyword missing",
+			listing:  Listing{Title: "Neubau mit Balkon"},
+			user:     users.UserConfig{IncludeKeywords: []string{"altbau", "dielen"}},
+			expected: false,
+		},
+		{
+			name:     "include pattern present",
+			listing:  Listing{Title: "3-Zimmer-Wohnung"},
+			user:     users.UserConfig{IncludePatterns: []string{`[23]-zimmer`}},
+			expected: true,
+		},
+		{
+			name:     "include pattern with uppercase umlaut",
+			listing:  Listing{Title: "Wohnung am Ufer"},
+			user:     users.UserConfig{IncludePatterns: []string{`Über|ufer`}},
+			expected: true,
+		},
+		{
+			name:     "exclude wins over include",
+			listing:  Listing{Title: "Altbau, Gewerbeeinheit"},
+			user:     users.UserConfig{IncludeKeywords: []string{"altbau"}, ExcludeKeywords: []string{"gewerbe"}},
+			expected: false,
+		},
+		{
+			name:     "invalid pattern is ignored",
+			listing:  Listing{Title: "Wohnung"},
+			user:     users.UserConfig{ExcludePatterns: []string{`(unclosed`}},
+			expected: true,
+		},
+		{
+			name:     "invalid include pattern never matches",
+			listing:  Listing{Title: "Wohnung"},
+			user:     users.UserConfig{IncludePatterns: []string{`(unclosed`}},
+			expected: false,
+		},
+		{
+			name:     "include filter with empty text",
+			listing:  Listing{},
+			user:     users.UserConfig{IncludeKeywords: []string{"altbau"}},
+			expected: false,
+		},
+		{
+			name:     "empty keywords are ignored",
+			listing:  Listing{Title: "Wohnung"},
+			user:     users.UserConfig{ExcludeKeywords: []string{"", "  "}},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.listing.MatchUserConfig(&tt.user); result != tt.expected {
+				t.Errorf("MatchUserConfig() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -1,23 +1,36 @@
 package common
 
 import (
+	"apartmenthunter/internal/geo"
 	"apartmenthunter/internal/telegram"
 	"apartmenthunter/internal/users"
+	"apartmenthunter/internal/wbs"
 	"fmt"
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 type Listing struct {
 	ID          string
 	Company     string
+	Title       string
+	Description string
 	Price       string
+	RentType    RentType // which rent Price holds
 	Size        string
 	Address     string
 	URL         string
 	ZipCode     string
-	WbsRequired bool
+	District    string  // Ortsteil, or Bezirk if only that is known
+	Lat         float64 // zero if not geocoded
+	Lon         float64
+	Commutes    map[geo.Point]int // public transport minutes per destination
+	Wbs         wbs.Requirement
+	Rooms       string
+	Floor       string // "0" is the ground floor, empty if unknown
+	Balcony     bool
 }
 
 // ToTelegramInfo converts a listing to telegram struct
@@ -32,15 +45,48 @@ func (l Listing) ToTelegramInfo() *telegram.TelegramInfo {
 		MapLink:     mapsLink,
 		ListingLink: l.URL,
 		Site:        l.Company,
+		Notes:       notes(l.rentNote(), l.wbsNote()),
 	}
 }
 
+// wbsNote names the WBS the listing requires, empty if none
+func (l Listing) wbsNote() string {
+	if !l.Wbs.Required {
+		return ""
+	}
+	return "requires " + l.Wbs.String()
+}
+
+func notes(lines ...string) []string {
+	var result []string
+	for _, line := range lines {
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
 func (l Listing) MatchUserConfig(userConfig *users.UserConfig) bool {
-	// Check zip code, WBS, price, size
-	return l.matchesZipCode(userConfig.ZipCodes) &&
-		l.matchesWbsRequirement(userConfig.WbsRequired) &&
+	// Check location, WBS, price, size
+	return l.matchesLocation(userConfig.ZipCodes, userConfig.Districts) &&
+		l.matchesGeo(userConfig) &&
+		l.matchesCommutes(userConfig.Commutes) &&
+		l.matchesWbsRequirement(userConfig) &&
 		l.matchesPriceRange(userConfig.MinPrice, userConfig.MaxPrice) &&
-		l.matchesSizeRange(userConfig.MinSqm, userConfig.MaxSqm)
+		l.matchesMaxWarmPerSqm(userConfig.MaxWarmPerSqm) &&
+		l.matchesSizeRange(userConfig.MinSqm, userConfig.MaxSqm) &&
+		l.matchesKeywords(userConfig)
+}
+
+// matchesLocation accepts a listing in one of the zip codes or districts. Without
+// either restriction every location matches.
+func (l Listing) matchesLocation(allowedZipCodes, allowedDistricts []string) bool {
+	if len(allowedZipCodes) == 0 && len(allowedDistricts) == 0 {
+		return true // No restriction
+	}
+	return (len(allowedZipCodes) > 0 && l.matchesZipCode(allowedZipCodes)) ||
+		l.matchesAnyDistrict(allowedDistricts)
 }
 
 func (l Listing) matchesZipCode(allowedZipCodes []string) bool {
@@ -56,12 +102,17 @@ func (l Listing) matchesZipCode(allowedZipCodes []string) bool {
 	return false
 }
 
-func (l Listing) matchesWbsRequirement(userWbsRequired bool) bool {
-	// If user requires WBS but listing doesn't have it, reject
-	if userWbsRequired && !l.WbsRequired {
+// matchesWbsRequirement checks the listing's WBS against the user's entitlement. Users
+// asking for WBS listings only are assumed to hold a WBS even without an entitlement.
+func (l Listing) matchesWbsRequirement(userConfig *users.UserConfig) bool {
+	if userConfig.WbsRequired && !l.Wbs.Required {
 		return false
 	}
-	return true
+	entitlement := userConfig.Wbs
+	if entitlement == nil && userConfig.WbsRequired {
+		entitlement = &wbs.Entitlement{}
+	}
+	return entitlement.Allows(l.Wbs)
 }
 
 func (l Listing) matchesPriceRange(minPrice, maxPrice int) bool {
@@ -69,10 +120,11 @@ func (l Listing) matchesPriceRange(minPrice, maxPrice int) bool {
 		return true // No price restriction
 	}
 
-	price, err := l.parseIntFromString(l.Price)
+	warm, err := l.PriceValue()
 	if err != nil {
 		return false
 	}
+	price := int(warm)
 
 	if minPrice > 0 && price < minPrice {
 		return false
@@ -106,17 +158,38 @@ func (l Listing) matchesSizeRange(minSqm, maxSqm int) bool {
 	return true
 }
 
-func (l Listing) parseIntFromString(s string) (int, error) {
-	re := regexp.MustCompile(`(\d+(?:\.\d+)?)`)
-	matches := re.FindStringSubmatch(s)
-	if len(matches) < 2 {
-		return 0, strconv.ErrSyntax
-	}
+// SizeValue returns the numeric size of the listing in m²
+func (l Listing) SizeValue() (float64, error) {
+	return parseFloatFromString(l.Size)
+}
 
-	numFloat, err := strconv.ParseFloat(matches[1], 64)
+// RoomsValue returns the number of rooms of the listing
+func (l Listing) RoomsValue() (float64, error) {
+	return parseFloatFromString(l.Rooms)
+}
+
+func (l Listing) parseIntFromString(s string) (int, error) {
+	numFloat, err := parseFloatFromString(s)
 	if err != nil {
 		return 0, err
 	}
 
 	return int(numFloat), nil
 }
+
+// numberPattern matches German numbers with thousands separators ("1.050,00") before
+// plain decimals with a point or comma ("799.50", "65,5")
+var numberPattern = regexp.MustCompile(`\d{1,3}(?:\.\d{3})+(?:,\d+)?|\d+(?:[.,]\d+)?`)
+
+func parseFloatFromString(s string) (float64, error) {
+	match := numberPattern.FindString(s)
+	if match == "" {
+		return 0, strconv.ErrSyntax
+	}
+
+	if strings.Contains(match, ",") || strings.Count(match, ".") > 1 ||
+		(strings.Contains(match, ".") && len(match)-strings.Index(match, ".") == 4) {
+		match = strings.ReplaceAll(match, ".", "")
+	}
+	return strconv.ParseFloat(strings.Replace(match, ",", ".", 1), 64)
+}
@@ -3,6 +3,7 @@ package common
 import (
 	"apartmenthunter/internal/telegram"
 	"apartmenthunter/internal/users"
+	"apartmenthunter/internal/wbs"
 	"strings"
 	"testing"
 )
@@ -242,10 +243,10 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "perfect match - all criteria met",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: true,
-				Price:       "800",
-				Size:        "60",
+				ZipCode: "12043",
+				Wbs:     wbs.Requirement{Required: true},
+				Price:   "800",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043", "12045"},
@@ -260,10 +261,10 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "zip code mismatch",
 			listing: Listing{
-				ZipCode:     "10115",
-				WbsRequired: true,
-				Price:       "800",
-				Size:        "60",
+				ZipCode: "10115",
+				Wbs:     wbs.Requirement{Required: true},
+				Price:   "800",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043", "12045"},
@@ -278,10 +279,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "WBS requirement not met",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "800",
-				Size:        "60",
+				ZipCode: "12043",
+				Price:   "800",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -294,12 +294,12 @@ func TestListing_MatchUserConfig(t *testing.T) {
 			expected: false,
 		},
 		{
-			name: "WBS not required by user, listing has WBS",
+			name: "user without WBS, listing requires WBS",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: true,
-				Price:       "800",
-				Size:        "60",
+				ZipCode: "12043",
+				Wbs:     wbs.Requirement{Required: true},
+				Price:   "800",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -309,15 +309,67 @@ func TestListing_MatchUserConfig(t *testing.T) {
 				MinSqm:      50,
 				MaxSqm:      70,
 			},
+			expected: false,
+		},
+		{
+			name: "user with WBS, listing requires WBS",
+			listing: Listing{
+				ZipCode: "12043",
+				Wbs:     wbs.Requirement{Required: true, Level: 140},
+				Price:   "800",
+				Size:    "60",
+			},
+			userConfig: users.UserConfig{
+				ZipCodes: []string{"12043"},
+				Wbs:      &wbs.Entitlement{Level: 100},
+				MinPrice: 500,
+				MaxPrice: 1000,
+				MinSqm:   50,
+				MaxSqm:   70,
+			},
+			expected: true,
+		},
+		{
+			name: "user WBS level too high for listing",
+			listing: Listing{
+				ZipCode: "12043",
+				Wbs:     wbs.Requirement{Required: true, Level: 140},
+				Price:   "800",
+				Size:    "60",
+			},
+			userConfig: users.UserConfig{
+				ZipCodes: []string{"12043"},
+				Wbs:      &wbs.Entitlement{Level: 180},
+				MinPrice: 500,
+				MaxPrice: 1000,
+				MinSqm:   50,
+				MaxSqm:   70,
+			},
+			expected: false,
+		},
+		{
+			name: "user with WBS, listing without WBS",
+			listing: Listing{
+				ZipCode: "12043",
+				Price:   "800",
+				Size:    "60",
+			},
+			userConfig: users.UserConfig{
+				ZipCodes: []string{"12043"},
+				Wbs:      &wbs.Entitlement{Level: 140},
+				MinPrice: 500,
+				MaxPrice: 1000,
+				MinSqm:   50,
+				MaxSqm:   70,
+			},
 			expected: true,
 		},
 		{
 			name: "price too low",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "400",
-				Size:        "60",
+				ZipCode: "12043",
+				Price:   "400",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -332,10 +384,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "price too high",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "1200",
-				Size:        "60",
+				ZipCode: "12043",
+				Price:   "1200",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -350,10 +401,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "size too small",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "800",
-				Size:        "40",
+				ZipCode: "12043",
+				Price:   "800",
+				Size:    "40",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -368,10 +418,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "size too large",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "800",
-				Size:        "80",
+				ZipCode: "12043",
+				Price:   "800",
+				Size:    "80",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -386,10 +435,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "no zip code restrictions",
 			listing: Listing{
-				ZipCode:     "99999",
-				WbsRequired: false,
-				Price:       "800",
-				Size:        "60",
+				ZipCode: "99999",
+				Price:   "800",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{},
@@ -404,10 +452,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "no price restrictions",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "2000",
-				Size:        "60",
+				ZipCode: "12043",
+				Price:   "2000",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -422,10 +469,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "no size restrictions",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "800",
-				Size:        "200",
+				ZipCode: "12043",
+				Price:   "800",
+				Size:    "200",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -440,10 +486,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "price with decimal values",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "799.50",
-				Size:        "60.5",
+				ZipCode: "12043",
+				Price:   "799.50",
+				Size:    "60.5",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -458,10 +503,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "price with currency symbol",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "€800",
-				Size:        "60 qm",
+				ZipCode: "12043",
+				Price:   "€800",
+				Size:    "60 qm",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -476,10 +520,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "unparseable price",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "auf Anfrage",
-				Size:        "60",
+				ZipCode: "12043",
+				Price:   "auf Anfrage",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -494,10 +537,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "unparseable size",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "800",
-				Size:        "variabel",
+				ZipCode: "12043",
+				Price:   "800",
+				Size:    "variabel",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -512,10 +554,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "only minimum price set",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "600",
-				Size:        "60",
+				ZipCode: "12043",
+				Price:   "600",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -530,10 +571,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "only maximum price set",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "800",
-				Size:        "60",
+				ZipCode: "12043",
+				Price:   "800",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -548,10 +588,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "only minimum size set",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "800",
-				Size:        "60",
+				ZipCode: "12043",
+				Price:   "800",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -566,10 +605,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "only maximum size set",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "800",
-				Size:        "60",
+				ZipCode: "12043",
+				Price:   "800",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -584,10 +622,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "empty zip code in listing",
 			listing: Listing{
-				ZipCode:     "",
-				WbsRequired: false,
-				Price:       "800",
-				Size:        "60",
+				ZipCode: "",
+				Price:   "800",
+				Size:    "60",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -602,10 +639,9 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		{
 			name: "complex price string with multiple numbers",
 			listing: Listing{
-				ZipCode:     "12043",
-				WbsRequired: false,
-				Price:       "Kaltmiete: 750€, Nebenkosten: 150€",
-				Size:        "60.5 qm",
+				ZipCode: "12043",
+				Price:   "Kaltmiete: 750€, Nebenkosten: 150€",
+				Size:    "60.5 qm",
 			},
 			userConfig: users.UserConfig{
 				ZipCodes:    []string{"12043"},
@@ -628,3 +664,11 @@ func TestListing_MatchUserConfig(t *testing.T) {
 		})
 	}
 }
+
+// TestListing_ToTelegramInfo_Wbs tests the note on required WBS
+func TestListing_ToTelegramInfo_Wbs(t *testing.T) {
+	info := Listing{Wbs: wbs.Requirement{Required: true, Level: 140}}.ToTelegramInfo()
+	if len(info.Notes) != 1 || info.Notes[0] != "requires WBS 140" {
+		t.Errorf("Notes = %v, want [requires WBS 140]", info.Notes)
+	}
+}
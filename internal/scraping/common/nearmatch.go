@@ -0,0 +1,109 @@
+package common
+
+import (
+	"apartmenthunter/internal/users"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Miss describes a filter criterion a listing failed within the user's tolerance
+type Miss struct {
+	Criterion string
+	Detail    string
+}
+
+// NearMatch reports whether a listing that fails the strict user filters passes
+// within the user's tolerance, and explains which criteria were missed by how much
+func (l Listing) NearMatch(userConfig *users.UserConfig) ([]Miss, bool) {
+	tolerance := userConfig.Tolerance
+	if tolerance == nil || l.MatchUserConfig(userConfig) {
+		return nil, false
+	}
+
+	// WBS, keywords, the geographic area and commutes are never relaxed
+	if !l.matchesWbsRequirement(userConfig) || !l.matchesKeywords(userConfig) ||
+		!l.matchesGeo(userConfig) || !l.matchesCommutes(userConfig.Commutes) {
+		return nil, false
+	}
+
+	var misses []Miss
+	for _, check := range []func(*users.UserConfig, *users.Tolerance) (*Miss, bool){
+		l.nearZipCode,
+		l.nearPrice,
+		l.nearSize,
+	} {
+		miss, ok := check(userConfig, tolerance)
+		if !ok {
+			return nil, false
+		}
+		if miss != nil {
+			misses = append(misses, *miss)
+		}
+	}
+	return misses, len(misses) > 0
+}
+
+func (l Listing) nearZipCode(userConfig *users.UserConfig, tolerance *users.Tolerance) (*Miss, bool) {
+	if l.matchesLocation(userConfig.ZipCodes, userConfig.Districts) {
+		return nil, true
+	}
+	for _, zipCode := range tolerance.ZipCodes {
+		if l.ZipCode == zipCode {
+			return &Miss{
+				Criterion: "zip code",
+				Detail:    fmt.Sprintf("zip code %s is a neighbouring area", l.ZipCode),
+			}, true
+		}
+	}
+	return nil, false
+}
+
+func (l Listing) nearPrice(userConfig *users.UserConfig, tolerance *users.Tolerance) (*Miss, bool) {
+	if l.matchesPriceRange(userConfig.MinPrice, userConfig.MaxPrice) {
+		return nil, true
+	}
+
+	price, err := l.PriceValue()
+	if err != nil || userConfig.MaxPrice == 0 || int(price) <= userConfig.MaxPrice {
+		return nil, false // unparseable or below the minimum, neither is tolerated
+	}
+
+	maxPrice := float64(userConfig.MaxPrice)
+	over := price - maxPrice
+	percent := over / maxPrice * 100
+	if percent > tolerance.PricePercent {
+		return nil, false
+	}
+	return &Miss{
+		Criterion: "rent",
+		Detail: fmt.Sprintf("rent %s € is %s € (%.1f%%) over your max of %d €",
+			formatAmount(price), formatAmount(over), percent, userConfig.MaxPrice),
+	}, true
+}
+
+func (l Listing) nearSize(userConfig *users.UserConfig, tolerance *users.Tolerance) (*Miss, bool) {
+	if l.matchesSizeRange(userConfig.MinSqm, userConfig.MaxSqm) {
+		return nil, true
+	}
+
+	size, err := l.SizeValue()
+	if err != nil || userConfig.MinSqm == 0 || int(size) >= userConfig.MinSqm {
+		return nil, false // unparseable or above the maximum, neither is tolerated
+	}
+
+	below := float64(userConfig.MinSqm) - size
+	if below > float64(tolerance.SqmBelow) {
+		return nil, false
+	}
+	return &Miss{
+		Criterion: "size",
+		Detail: fmt.Sprintf("size %s m² is %s m² below your min of %d m²",
+			formatAmount(size), formatAmount(below), userConfig.MinSqm),
+	}, true
+}
+
+// formatAmount prints a number with at most two decimals and without trailing zeros
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
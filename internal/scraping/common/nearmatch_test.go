@@ -0,0 +1,136 @@
+package common
+
+import (
+	"apartmenthunter/internal/users"
+	"testing"
+)
+
+func toleranceUser() users.UserConfig {
+	return users.UserConfig{
+		ZipCodes: []string{"12043"},
+		MinPrice: 500,
+		MaxPrice: 1000,
+		MinSqm:   40,
+		MaxSqm:   70,
+		Tolerance: &users.Tolerance{
+			PricePercent: 5,
+			SqmBelow:     3,
+			ZipCodes:     []string{"12057"},
+		},
+	}
+}
+
+// TestListing_NearMatch tests near match detection and the explanation of misses
+func TestListing_NearMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		listing     Listing
+		modify      func(*users.UserConfig)
+		wantMatch   bool
+		wantDetails []string
+	}{
+		{
+			name:      "full match is not a near match",
+			listing:   Listing{ZipCode: "12043", Price: "800", Size: "50"},
+			wantMatch: false,
+		},
+		{
+			name:        "rent slightly over budget",
+			listing:     Listing{ZipCode: "12043", Price: "1010", Size: "50"},
+			wantMatch:   true,
+			wantDetails: []string{"rent 1010 € is 10 € (1.0%) over your max of 1000 €"},
+		},
+		{
+			name:        "rent at tolerance limit",
+			listing:     Listing{ZipCode: "12043", Price: "1050", Size: "50"},
+			wantMatch:   true,
+			wantDetails: []string{"rent 1050 € is 50 € (5.0%) over your max of 1000 €"},
+		},
+		{
+			name:      "rent beyond tolerance",
+			listing:   Listing{ZipCode: "12043", Price: "1060", Size: "50"},
+			wantMatch: false,
+		},
+		{
+			name:      "rent below minimum is not tolerated",
+			listing:   Listing{ZipCode: "12043", Price: "450", Size: "50"},
+			wantMatch: false,
+		},
+		{
+			name:        "size slightly too small",
+			listing:     Listing{ZipCode: "12043", Price: "800", Size: "37.5"},
+			wantMatch:   true,
+			wantDetails: []string{"size 37.5 m² is 2.5 m² below your min of 40 m²"},
+		},
+		{
+			name:      "size beyond tolerance",
+			listing:   Listing{ZipCode: "12043", Price: "800", Size: "36"},
+			wantMatch: false,
+		},
+		{
+			name:      "size above maximum is not tolerated",
+			listing:   Listing{ZipCode: "12043", Price: "800", Size: "75"},
+			wantMatch: false,
+		},
+		{
+			name:        "neighbouring zip code",
+			listing:     Listing{ZipCode: "12057", Price: "800", Size: "50"},
+			wantMatch:   true,
+			wantDetails: []string{"zip code 12057 is a neighbouring area"},
+		},
+		{
+			name:      "unrelated zip code",
+			listing:   Listing{ZipCode: "10115", Price: "1010", Size: "50"},
+			wantMatch: false,
+		},
+		{
+			name:      "unparseable rent",
+			listing:   Listing{ZipCode: "12057", Price: "auf Anfrage", Size: "50"},
+			wantMatch: false,
+		},
+		{
+			name:      "wbs is never relaxed",
+			listing:   Listing{ZipCode: "12043", Price: "1010", Size: "50"},
+			modify:    func(u *users.UserConfig) { u.WbsRequired = true },
+			wantMatch: false,
+		},
+		{
+			name:      "no tolerance configured",
+			listing:   Listing{ZipCode: "12043", Price: "1010", Size: "50"},
+			modify:    func(u *users.UserConfig) { u.Tolerance = nil },
+			wantMatch: false,
+		},
+		{
+			name:      "several misses",
+			listing:   Listing{ZipCode: "12057", Price: "1020", Size: "39"},
+			wantMatch: true,
+			wantDetails: []string{
+				"zip code 12057 is a neighbouring area",
+				"rent 1020 € is 20 € (2.0%) over your max of 1000 €",
+				"size 39 m² is 1 m² below your min of 40 m²",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := toleranceUser()
+			if tt.modify != nil {
+				tt.modify(&user)
+			}
+
+			misses, ok := tt.listing.NearMatch(&user)
+			if ok != tt.wantMatch {
+				t.Fatalf("NearMatch() = %v, want %v (misses %v)", ok, tt.wantMatch, misses)
+			}
+			if len(misses) != len(tt.wantDetails) {
+				t.Fatalf("NearMatch() returned %d misses, want %d: %v", len(misses), len(tt.wantDetails), misses)
+			}
+			for i, want := range tt.wantDetails {
+				if misses[i].Detail != want {
+					t.Errorf("miss %d = %q, want %q", i, misses[i].Detail, want)
+				}
+			}
+		})
+	}
+}
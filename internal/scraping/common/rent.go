@@ -0,0 +1,124 @@
+package common
+
+import (
+	"apartmenthunter/internal/config"
+	"fmt"
+	"strings"
+)
+
+// RentType tells which rent a scraper extracted into Listing.Price
+type RentType int
+
+const (
+	RentUnknown RentType = iota // used as is
+	RentWarm                    // Warmmiete / Gesamtmiete, including Nebenkosten
+	RentCold                    // Kaltmiete / Nettokaltmiete
+)
+
+func (t RentType) String() string {
+	switch t {
+	case RentWarm:
+		return "warm"
+	case RentCold:
+		return "cold"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText stores the rent type by name, e.g. in the archive
+func (t RentType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText reads a rent type written by MarshalText, unknown names are RentUnknown
+func (t *RentType) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "warm":
+		*t = RentWarm
+	case "cold":
+		*t = RentCold
+	default:
+		*t = RentUnknown
+	}
+	return nil
+}
+
+// DetectRentType reads the rent type from a label like "Kaltmiete" or "Gesamtmiete",
+// returning fallback if the label names neither
+func DetectRentType(label string, fallback RentType) RentType {
+	text := NormalizeText(label)
+	switch {
+	case strings.Contains(text, "kalt") || strings.Contains(text, "netto"):
+		return RentCold
+	case strings.Contains(text, "warm") || strings.Contains(text, "gesamt") || strings.Contains(text, "brutto"):
+		return RentWarm
+	default:
+		return fallback
+	}
+}
+
+// PriceValue returns the warm rent of the listing. A cold rent is converted with
+// config.NebenkostenPerSqm, or used as is if the size is unknown.
+func (l Listing) PriceValue() (float64, error) {
+	price, err := parseFloatFromString(l.Price)
+	if err != nil || l.RentType != RentCold {
+		return price, err
+	}
+	size, err := l.SizeValue()
+	if err != nil {
+		return price, nil
+	}
+	return price + size*config.NebenkostenPerSqm, nil
+}
+
+// ColdPriceValue returns the cold rent of the listing, estimated from a warm rent the
+// same way PriceValue estimates the warm rent
+func (l Listing) ColdPriceValue() (float64, error) {
+	price, err := parseFloatFromString(l.Price)
+	if err != nil || l.RentType != RentWarm {
+		return price, err
+	}
+	size, err := l.SizeValue()
+	if err != nil {
+		return price, nil
+	}
+	return max(0, price-size*config.NebenkostenPerSqm), nil
+}
+
+// WarmPerSqm returns the warm rent per m²
+func (l Listing) WarmPerSqm() (float64, bool) {
+	price, err := l.PriceValue()
+	if err != nil {
+		return 0, false
+	}
+	size, err := l.SizeValue()
+	if err != nil || size == 0 {
+		return 0, false
+	}
+	return price / size, true
+}
+
+func (l Listing) matchesMaxWarmPerSqm(maxWarmPerSqm float64) bool {
+	if maxWarmPerSqm <= 0 {
+		return true // No restriction
+	}
+	perSqm, ok := l.WarmPerSqm()
+	return ok && perSqm <= maxWarmPerSqm
+}
+
+// rentNote explains an estimated warm rent, empty if the scraped rent was warm already
+func (l Listing) rentNote() string {
+	if l.RentType != RentCold {
+		return ""
+	}
+	cold, err := parseFloatFromString(l.Price)
+	if err != nil {
+		return ""
+	}
+	warm, err := l.PriceValue()
+	if err != nil || warm == cold {
+		return ""
+	}
+	return fmt.Sprintf("warm rent estimated at %s € from %s € cold", formatAmount(warm), formatAmount(cold))
+}
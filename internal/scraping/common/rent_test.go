@@ -0,0 +1,165 @@
+package common
+
+import (
+	"apartmenthunter/internal/config"
+	"apartmenthunter/internal/users"
+	"math"
+	"testing"
+)
+
+// TestParseFloatFromString tests German and international number formats
+func TestParseFloatFromString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+		wantErr  bool
+	}{
+		{"799.50", 799.5, false},
+		{"1.050,00 €", 1050, false},
+		{"1.050 €", 1050, false},
+		{"12.345.678,9", 12345678.9, false},
+		{"65,5 m²", 65.5, false},
+		{"ab 650,00€", 650, false},
+		{"2,5 Zimmer", 2.5, false},
+		{"60.5 qm", 60.5, false},
+		{"Kaltmiete: 750€, Nebenkosten: 150€", 750, false},
+		{"auf Anfrage", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := parseFloatFromString(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFloatFromString(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("parseFloatFromString(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestDetectRentType tests reading the rent type from labels
+func TestDetectRentType(t *testing.T) {
+	tests := []struct {
+		label    string
+		fallback RentType
+		expected RentType
+	}{
+		{"Kaltmiete", RentWarm, RentCold},
+		{"Nettokaltmiete: 650,00 €", RentUnknown, RentCold},
+		{"Warmmiete", RentUnknown, RentWarm},
+		{"Gesamtmiete 850 €", RentCold, RentWarm},
+		{"Miete", RentWarm, RentWarm},
+		{"", RentUnknown, RentUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if result := DetectRentType(tt.label, tt.fallback); result != tt.expected {
+				t.Errorf("DetectRentType(%q) = %v, want %v", tt.label, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestListing_RentNormalisation tests estimating warm and cold rent from each other
+func TestListing_RentNormalisation(t *testing.T) {
+	nk := config.NebenkostenPerSqm
+
+	tests := []struct {
+		name     string
+		listing  Listing
+		wantWarm float64
+		wantCold float64
+	}{
+		{"warm rent", Listing{Price: "900", Size: "50", RentType: RentWarm}, 900, 900 - 50*nk},
+		{"cold rent", Listing{Price: "750", Size: "50", RentType: RentCold}, 750 + 50*nk, 750},
+		{"unknown rent type is used as is", Listing{Price: "800", Size: "50"}, 800, 800},
+		{"cold rent without size", Listing{Price: "750", RentType: RentCold}, 750, 750},
+		{"german number format", Listing{Price: "1.050,00", Size: "70,5", RentType: RentCold}, 1050 + 70.5*nk, 1050},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warm, err := tt.listing.PriceValue()
+			if err != nil || math.Abs(warm-tt.wantWarm) > 0.001 {
+				t.Errorf("PriceValue() = %v, %v, want %v", warm, err, tt.wantWarm)
+			}
+			cold, err := tt.listing.ColdPriceValue()
+			if err != nil || math.Abs(cold-tt.wantCold) > 0.001 {
+				t.Errorf("ColdPriceValue() = %v, %v, want %v", cold, err, tt.wantCold)
+			}
+		})
+	}
+}
+
+// TestListing_MatchesWarmRent tests that price filters apply to the warm rent
+func TestListing_MatchesWarmRent(t *testing.T) {
+	nk := config.NebenkostenPerSqm
+
+	tests := []struct {
+		name     string
+		listing  Listing
+		user     users.UserConfig
+		expected bool
+	}{
+		{
+			name:     "cold rent within budget but warm rent over",
+			listing:  Listing{Price: "950", Size: "50", RentType: RentCold},
+			user:     users.UserConfig{MaxPrice: int(950 + 50*nk - 1)},
+			expected: false,
+		},
+		{
+			name:     "cold rent with warm rent within budget",
+			listing:  Listing{Price: "700", Size: "50", RentType: RentCold},
+			user:     users.UserConfig{MaxPrice: int(700 + 50*nk)},
+			expected: true,
+		},
+		{
+			name:     "warm per m² within limit",
+			listing:  Listing{Price: "750", Size: "50", RentType: RentWarm},
+			user:     users.UserConfig{MaxWarmPerSqm: 15},
+			expected: true,
+		},
+		{
+			name:     "warm per m² over limit",
+			listing:  Listing{Price: "800", Size: "50", RentType: RentWarm},
+			user:     users.UserConfig{MaxWarmPerSqm: 15},
+			expected: false,
+		},
+		{
+			name:     "estimated warm per m² over limit",
+			listing:  Listing{Price: "700", Size: "50", RentType: RentCold},
+			user:     users.UserConfig{MaxWarmPerSqm: 14 + nk - 0.1},
+			expected: false,
+		},
+		{
+			name:     "unknown size with per m² limit",
+			listing:  Listing{Price: "700", RentType: RentWarm},
+			user:     users.UserConfig{MaxWarmPerSqm: 15},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.listing.MatchUserConfig(&tt.user); result != tt.expected {
+				t.Errorf("MatchUserConfig() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestListing_ToTelegramInfo_EstimatedRent tests the note on estimated warm rents
+func TestListing_ToTelegramInfo_EstimatedRent(t *testing.T) {
+	info := Listing{Price: "700", Size: "50", RentType: RentCold}.ToTelegramInfo()
+	want := "warm rent estimated at " + formatAmount(700+50*config.NebenkostenPerSqm) + " € from 700 € cold"
+	if len(info.Notes) != 1 || info.Notes[0] != want {
+		t.Errorf("Notes = %v, want [%s]", info.Notes, want)
+	}
+
+	if info := (Listing{Price: "700", Size: "50", RentType: RentWarm}).ToTelegramInfo(); len(info.Notes) != 0 {
+		t.Errorf("Notes = %v, want none for a warm rent", info.Notes)
+	}
+}
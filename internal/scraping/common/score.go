@@ -0,0 +1,148 @@
+package common
+
+import (
+	"apartmenthunter/internal/users"
+	"sort"
+)
+
+// Score rates a listing from 0 to 100 against the user's preferences. Criteria without
+// data (e.g. unknown floor) are left out of the weighting. Returns false if the user
+// has no preferences or no criterion could be evaluated.
+func (l Listing) Score(userConfig *users.UserConfig) (float64, bool) {
+	prefs := userConfig.Preferences
+	if prefs == nil {
+		return 0, false
+	}
+
+	var total, weights float64
+	add := func(weight, score float64, ok bool) {
+		if weight <= 0 || !ok {
+			return
+		}
+		total += weight * clamp(score)
+		weights += weight
+	}
+
+	score, ok := l.pricePerSqmScore(prefs.TargetPricePerSqm)
+	add(prefs.Weights.PricePerSqm, score, ok)
+	score, ok = l.sizeScore(userConfig.MinSqm, userConfig.MaxSqm)
+	add(prefs.Weights.Size, score, ok)
+	score, ok = l.roomsScore(prefs.MinRooms)
+	add(prefs.Weights.Rooms, score, ok)
+	score, ok = l.districtScore(prefs.Districts)
+	add(prefs.Weights.District, score, ok)
+	score, ok = l.floorScore(prefs.MinFloor)
+	add(prefs.Weights.Floor, score, ok)
+	add(prefs.Weights.Balcony, l.balconyScore(), true)
+
+	if weights == 0 {
+		return 0, false
+	}
+	return total / weights * 100, true
+}
+
+// RankByScore sorts listings by descending score for the user, keeping the original
+// order for equal scores
+func RankByScore(listings []Listing, userConfig *users.UserConfig) {
+	type scored struct {
+		listing Listing
+		score   float64
+	}
+	ranked := make([]scored, len(listings))
+	for i, l := range listings {
+		score, _ := l.Score(userConfig)
+		ranked[i] = scored{l, score}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+	for i := range ranked {
+		listings[i] = ranked[i].listing
+	}
+}
+
+// RankedMatches returns the listings matching the user's filters, best score first
+func RankedMatches(listings []Listing, userConfig *users.UserConfig) []Listing {
+	var matches []Listing
+	for _, l := range listings {
+		if l.MatchUserConfig(userConfig) {
+			matches = append(matches, l)
+		}
+	}
+	RankByScore(matches, userConfig)
+	return matches
+}
+
+func (l Listing) pricePerSqmScore(target float64) (float64, bool) {
+	if target <= 0 {
+		return 0, false
+	}
+	price, err := l.PriceValue()
+	if err != nil {
+		return 0, false
+	}
+	size, err := l.SizeValue()
+	if err != nil || size == 0 {
+		return 0, false
+	}
+	return 1 - (price/size-target)/target, true
+}
+
+func (l Listing) sizeScore(minSqm, maxSqm int) (float64, bool) {
+	if maxSqm <= minSqm {
+		return 0, false
+	}
+	size, err := l.SizeValue()
+	if err != nil {
+		return 0, false
+	}
+	return (size - float64(minSqm)) / float64(maxSqm-minSqm), true
+}
+
+func (l Listing) roomsScore(minRooms float64) (float64, bool) {
+	if minRooms <= 0 {
+		return 0, false
+	}
+	rooms, err := l.RoomsValue()
+	if err != nil {
+		return 0, false
+	}
+	return rooms / minRooms, true
+}
+
+func (l Listing) districtScore(districts []string) (float64, bool) {
+	if len(districts) == 0 {
+		return 0, false
+	}
+	for i, district := range districts {
+		if l.ZipCode == district || l.InDistrict(district) {
+			return 1 - float64(i)/float64(len(districts)), true
+		}
+	}
+	return 0, true
+}
+
+func (l Listing) floorScore(minFloor int) (float64, bool) {
+	if minFloor <= 0 || l.Floor == "" {
+		return 0, false
+	}
+	floor, err := l.parseIntFromString(l.Floor)
+	if err != nil {
+		return 0, false
+	}
+	if floor < minFloor {
+		return 0, true
+	}
+	return 1, true
+}
+
+func (l Listing) balconyScore() float64 {
+	if l.Balcony {
+		return 1
+	}
+	return 0
+}
+
+func clamp(score float64) float64 {
+	return max(0, min(1, score))
+}
@@ -0,0 +1,179 @@
+package common
+
+import (
+	"apartmenthunter/internal/users"
+	"math"
+	"testing"
+)
+
+func scoringUser(weights users.Weights) *users.UserConfig {
+	return &users.UserConfig{
+		MinSqm: 40,
+		MaxSqm: 80,
+		Preferences: &users.Preferences{
+			TargetPricePerSqm: 12,
+			MinRooms:          2,
+			Districts:         []string{"Neukölln", "Kreuzberg"},
+			MinFloor:          1,
+			Weights:           weights,
+		},
+	}
+}
+
+// TestListing_Score tests the individual scoring criteria
+func TestListing_Score(t *testing.T) {
+	tests := []struct {
+		name      string
+		listing   Listing
+		weights   users.Weights
+		wantScore float64
+		wantOk    bool
+	}{
+		{
+			name:      "price per m² at target",
+			listing:   Listing{Price: "600", Size: "50"},
+			weights:   users.Weights{PricePerSqm: 1},
+			wantScore: 100,
+			wantOk:    true,
+		},
+		{
+			name:      "price per m² 50% over target",
+			listing:   Listing{Price: "900", Size: "50"},
+			weights:   users.Weights{PricePerSqm: 1},
+			wantScore: 50,
+			wantOk:    true,
+		},
+		{
+			name:      "price per m² twice the target",
+			listing:   Listing{Price: "1200", Size: "50"},
+			weights:   users.Weights{PricePerSqm: 1},
+			wantScore: 0,
+			wantOk:    true,
+		},
+		{
+			name:      "size in the middle of the range",
+			listing:   Listing{Size: "60"},
+			weights:   users.Weights{Size: 1},
+			wantScore: 50,
+			wantOk:    true,
+		},
+		{
+			name:      "fewer rooms than wanted",
+			listing:   Listing{Rooms: "1"},
+			weights:   users.Weights{Rooms: 1},
+			wantScore: 50,
+			wantOk:    true,
+		},
+		{
+			name:      "more rooms than wanted",
+			listing:   Listing{Rooms: "3"},
+			weights:   users.Weights{Rooms: 1},
+			wantScore: 100,
+			wantOk:    true,
+		},
+		{
+			name:      "second preferred district",
+			listing:   Listing{District: "Kreuzberg"},
+			weights:   users.Weights{District: 1},
+			wantScore: 50,
+			wantOk:    true,
+		},
+		{
+			name:      "preferred district by zip code",
+			listing:   Listing{ZipCode: "12047"},
+			weights:   users.Weights{District: 1},
+			wantScore: 100,
+			wantOk:    true,
+		},
+		{
+			name:      "other district",
+			listing:   Listing{ZipCode: "10247", District: "Friedrichshain"},
+			weights:   users.Weights{District: 1},
+			wantScore: 0,
+			wantOk:    true,
+		},
+		{
+			name:      "ground floor below minimum",
+			listing:   Listing{Floor: "0"},
+			weights:   users.Weights{Floor: 1},
+			wantScore: 0,
+			wantOk:    true,
+		},
+		{
+			name:    "unknown floor is not scored",
+			listing: Listing{},
+			weights: users.Weights{Floor: 1},
+			wantOk:  false,
+		},
+		{
+			name:      "balcony",
+			listing:   Listing{Balcony: true},
+			weights:   users.Weights{Balcony: 1},
+			wantScore: 100,
+			wantOk:    true,
+		},
+		{
+			name:      "weighted combination",
+			listing:   Listing{Price: "600", Size: "50", Balcony: false},
+			weights:   users.Weights{PricePerSqm: 3, Balcony: 1},
+			wantScore: 75,
+			wantOk:    true,
+		},
+		{
+			name:      "criteria without data are left out",
+			listing:   Listing{Price: "600", Size: "50", Rooms: ""},
+			weights:   users.Weights{PricePerSqm: 1, Rooms: 5, Floor: 5},
+			wantScore: 100,
+			wantOk:    true,
+		},
+		{
+			name:    "no weights",
+			listing: Listing{Price: "600", Size: "50"},
+			weights: users.Weights{},
+			wantOk:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, ok := tt.listing.Score(scoringUser(tt.weights))
+			if ok != tt.wantOk {
+				t.Fatalf("Score() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if math.Abs(score-tt.wantScore) > 0.01 {
+				t.Errorf("Score() = %.2f, want %.2f", score, tt.wantScore)
+			}
+		})
+	}
+}
+
+// TestListing_Score_NoPreferences tests that users without preferences are not scored
+func TestListing_Score_NoPreferences(t *testing.T) {
+	if _, ok := (Listing{Price: "600", Size: "50"}).Score(&users.UserConfig{}); ok {
+		t.Error("Score() should not score without preferences")
+	}
+}
+
+// TestRankedMatches tests filtering and ordering by score
+func TestRankedMatches(t *testing.T) {
+	user := scoringUser(users.Weights{PricePerSqm: 1, Balcony: 1})
+	user.MaxPrice = 1000
+	listings := []Listing{
+		{ID: "no balcony", Price: "600", Size: "50"},
+		{ID: "too expensive", Price: "1100", Size: "50", Balcony: true},
+		{ID: "best", Price: "600", Size: "50", Balcony: true},
+		{ID: "pricey", Price: "900", Size: "50", Balcony: true},
+	}
+
+	ranked := RankedMatches(listings, user)
+
+	want := []string{"best", "pricey", "no balcony"}
+	if len(ranked) != len(want) {
+		t.Fatalf("RankedMatches() returned %d listings, want %d", len(ranked), len(want))
+	}
+	for i, id := range want {
+		if ranked[i].ID != id {
+			t.Errorf("RankedMatches()[%d] = %s, want %s", i, ranked[i].ID, id)
+		}
+	}
+}
@@ -2,12 +2,19 @@ package common
 
 import (
 	"apartmenthunter/internal/bot"
+	"apartmenthunter/internal/geo"
 	"apartmenthunter/internal/http"
 	"apartmenthunter/internal/store"
+	"apartmenthunter/internal/transit"
 	"context"
+	"errors"
 	"fmt"
 )
 
+// ErrUnchanged is returned by scrapers whose result page did not change since the last
+// scrape, the previous listings are still current
+var ErrUnchanged = errors.New("listings unchanged")
+
 type Scraper interface {
 	GetName() string
 	Scrape(ctx context.Context) ([]Listing, error)
@@ -20,6 +27,8 @@ type BaseScraper struct {
 	HTTPClient      http.HTTPClient
 	HeaderGenerator *bot.HeaderGenerator
 	State           *store.ScraperState
+	Geocoder        *geo.Geocoder      // optional, listings are not geocoded if nil
+	Commutes        *transit.Estimator // optional, commutes are not estimated if nil
 	name            string
 	scrapingFunc    ScrapingFunc
 }
@@ -43,6 +52,10 @@ func (b *BaseScraper) Scrape(ctx context.Context) ([]Listing, error) {
 	if err != nil {
 		return nil, fmt.Errorf("fetching %s listings: %w", b.name, err)
 	}
+	for i := range listings {
+		listings[i].EnrichLocation(b.Geocoder)
+		listings[i].EstimateCommutes(b.Commutes)
+	}
 	return listings, nil
 }
 
@@ -3,6 +3,7 @@ package dewego
 import (
 	"apartmenthunter/internal/config"
 	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/wbs"
 	"context"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
@@ -57,7 +58,7 @@ func fetchSearchPage(ctx context.Context, base *common.BaseScraper, offset, limi
 	formData := buildFormData()
 	formData["tx_openimmo_immobilie[page]"] = []string{strconv.Itoa((offset / limit) + 1)}
 
-	headers := base.HeaderGenerator.GenerateGeneralRequestHeaders("", "", true, false)
+	headers := base.HeaderGenerator.GenerateGeneralRequestHeaders(config.DewegoURL, "", "", true, false)
 
 	resp, err := base.HTTPClient.Post(ctx, config.DewegoURL, formData, headers)
 	if err != nil {
@@ -112,8 +113,13 @@ func parseListings(doc *goquery.Document) []common.Listing {
 		sizeText := strings.TrimSpace(s.Find("ul.article__properties li:nth-child(2) span.text").Text())
 		size := strings.TrimSuffix(sizeText, " m²")
 
+		roomsText := strings.TrimSpace(s.Find("ul.article__properties li:nth-child(1) span.text").Text())
+		rooms, _ := common.ExtractRooms(roomsText)
+		floor, _ := common.ExtractFloor(title)
+
 		rentText := strings.TrimSpace(s.Find("div.article__price-tag span.price").Text())
 		rent := strings.TrimSuffix(rentText, " €")
+		rentType := common.DetectRentType(s.Find("div.article__price-tag").Text(), common.RentWarm)
 
 		listingLink, exists := s.Find("a[target=_blank]").Attr("href")
 		if !exists {
@@ -123,13 +129,19 @@ func parseListings(doc *goquery.Document) []common.Listing {
 		}
 
 		listings = append(listings, common.Listing{
-			ID:          postID,
-			Company:     "Dewego",
-			Price:       rent,
-			Size:        size,
-			Address:     fullAddress,
-			URL:         listingLink,
-			WbsRequired: common.FilterWBSString(title),
+			ID:       postID,
+			Company:  "Dewego",
+			Title:    title,
+			Price:    rent,
+			RentType: rentType,
+			Size:     size,
+			Address:  fullAddress,
+			District: neighborhood,
+			URL:      listingLink,
+			Wbs:      wbs.Parse(title),
+			Rooms:    rooms,
+			Floor:    floor,
+			Balcony:  common.HasBalcony(title),
 		})
 	})
 
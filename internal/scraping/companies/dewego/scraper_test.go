@@ -3,6 +3,7 @@ package dewego
 import (
 	"apartmenthunter/internal/config"
 	"apartmenthunter/internal/http"
+	"apartmenthunter/internal/http/replay"
 	"apartmenthunter/internal/scraping/common"
 	"apartmenthunter/internal/store"
 	"context"
@@ -14,9 +15,7 @@ import (
 
 // integration and contract tests for dewego
 func TestDewegoEndpoint_Reachability(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	client := http.NewClient(10 * time.Second)
 	headers := map[string]string{
@@ -39,9 +38,7 @@ func TestDewegoEndpoint_Reachability(t *testing.T) {
 
 // TestDewegoScraper_RealEndpoint tests against the actual Dewego search endpoint
 func TestDewegoScraper_RealEndpoint(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	// Create real dependencies
 	httpClient := http.NewClient(30 * time.Second)
@@ -62,9 +59,7 @@ func TestDewegoScraper_RealEndpoint(t *testing.T) {
 
 // TestDewegoScraper_DataStructure validates that our form data is accepted by the endpoint
 func TestDewegoScraper_DataStructure(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	httpClient := http.NewClient(30 * time.Second)
 	formData := buildFormData()
@@ -104,9 +99,7 @@ func TestDewegoScraper_DataStructure(t *testing.T) {
 
 // TestDewegoScraper_CSSSelectors validates that our CSS selectors work when listings exist
 func TestDewegoScraper_CSSSelectors(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	httpClient := http.NewClient(30 * time.Second)
 	formData := buildFormData()
@@ -196,3 +189,16 @@ func TestDewegoScraper_CSSSelectors(t *testing.T) {
 		t.Logf("SUCCESS: All required CSS selectors found in all %d articles", totalArticles)
 	}
 }
+
+// TestDewegoScraper_Replay tests parsing of recorded responses against the golden listings,
+// run with -refresh to record them again
+func TestDewegoScraper_Replay(t *testing.T) {
+	client := replay.Client(t, "testdata/fixtures.json", http.NewClient(30*time.Second))
+	scraper := common.NewBaseScraper(client, store.NewScraperState(), "Dewego", FetchListings)
+
+	listings, err := scraper.Scrape(context.Background())
+	if err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	replay.Golden(t, "testdata/listings.golden", replay.Lines(listings))
+}
@@ -3,6 +3,7 @@ package gewobag
 import (
 	"apartmenthunter/internal/config"
 	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/wbs"
 	"context"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
@@ -12,12 +13,15 @@ import (
 )
 
 func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.Listing, error) {
-	headers := base.HeaderGenerator.GenerateGeneralRequestHeaders("", "", false, false)
+	headers := base.HeaderGenerator.GenerateGeneralRequestHeaders(config.GewobagURL, "", "", false, false)
 
 	resp, err := base.HTTPClient.Get(ctx, config.GewobagURL, headers)
 	if err != nil {
 		return nil, fmt.Errorf("error making get request: %w", err)
 	}
+	if resp.NotModified {
+		return nil, common.ErrUnchanged
+	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, fmt.Errorf("HTTP error: status code %d", resp.StatusCode)
@@ -33,7 +37,6 @@ func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.List
 		postID, _ := s.Attr("id")
 
 		title := strings.TrimSpace(s.Find("tr.angebot-address td h3.angebot-title").Text())
-		isWbs := common.FilterWBSString(title)
 		address := strings.TrimSpace(s.Find("tr.angebot-address td address").Text())
 		zip, ok := common.ExtractZIP(address)
 		if !ok {
@@ -44,10 +47,13 @@ func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.List
 		if !ok {
 			log.Println("[Gewobag] Error extracting size", area)
 		}
+		rooms, _ := common.ExtractRooms(area)
+		floor, _ := common.ExtractFloor(title)
 
 		cost := strings.TrimSpace(s.Find("tr.angebot-kosten td").Text())
 		cost = strings.TrimSuffix(cost, "€")
 		cost = strings.TrimPrefix(cost, "ab ")
+		rentType := common.DetectRentType(s.Find("tr.angebot-kosten th").Text(), common.RentWarm)
 
 		listingLink, found := s.Find("a.read-more-link").Attr("href")
 		if !found {
@@ -55,14 +61,19 @@ func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.List
 		}
 
 		listings = append(listings, common.Listing{
-			ID:          postID,
-			Company:     "Gewobag",
-			Price:       cost,
-			Size:        size,
-			Address:     address,
-			URL:         listingLink,
-			WbsRequired: isWbs,
-			ZipCode:     zip,
+			ID:       postID,
+			Company:  "Gewobag",
+			Title:    title,
+			Price:    cost,
+			RentType: rentType,
+			Size:     size,
+			Address:  address,
+			URL:      listingLink,
+			Wbs:      wbs.Parse(title),
+			ZipCode:  zip,
+			Rooms:    rooms,
+			Floor:    floor,
+			Balcony:  common.HasBalcony(title),
 		})
 	})
 	return listings, nil
@@ -3,6 +3,7 @@ package gewobag
 import (
 	"apartmenthunter/internal/config"
 	"apartmenthunter/internal/http"
+	"apartmenthunter/internal/http/replay"
 	"apartmenthunter/internal/scraping/common"
 	"apartmenthunter/internal/store"
 	"context"
@@ -14,9 +15,7 @@ import (
 
 // TestGewobagEndpoint_Reachability tests if the Gewobag endpoint is accessible
 func TestGewobagEndpoint_Reachability(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	client := http.NewClient(10 * time.Second)
 	headers := map[string]string{
@@ -39,9 +38,7 @@ func TestGewobagEndpoint_Reachability(t *testing.T) {
 
 // TestGewobagScraper_RealEndpoint tests against the actual Gewobag search endpoint
 func TestGewobagScraper_RealEndpoint(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	// Create real dependencies
 	httpClient := http.NewClient(30 * time.Second)
@@ -62,9 +59,7 @@ func TestGewobagScraper_RealEndpoint(t *testing.T) {
 
 // TestGewobagScraper_HTMLStructure validates the HTML structure we depend on
 func TestGewobagScraper_HTMLStructure(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	httpClient := http.NewClient(30 * time.Second)
 	headers := map[string]string{
@@ -102,9 +97,7 @@ func TestGewobagScraper_HTMLStructure(t *testing.T) {
 
 // TestGewobagScraper_CSSSelectors validates that our CSS selectors work when listings exist
 func TestGewobagScraper_CSSSelectors(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	httpClient := http.NewClient(30 * time.Second)
 	headers := map[string]string{
@@ -192,3 +185,16 @@ func TestGewobagScraper_CSSSelectors(t *testing.T) {
 		t.Logf("SUCCESS: All required CSS selectors found in all %d articles", totalArticles)
 	}
 }
+
+// TestGewobagScraper_Replay tests parsing of recorded responses against the golden listings,
+// run with -refresh to record them again
+func TestGewobagScraper_Replay(t *testing.T) {
+	client := replay.Client(t, "testdata/fixtures.json", http.NewClient(30*time.Second))
+	scraper := common.NewBaseScraper(client, store.NewScraperState(), "Gewobag", FetchListings)
+
+	listings, err := scraper.Scrape(context.Background())
+	if err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	replay.Golden(t, "testdata/listings.golden", replay.Lines(listings))
+}
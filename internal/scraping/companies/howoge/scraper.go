@@ -3,15 +3,18 @@ package howoge
 import (
 	"apartmenthunter/internal/config"
 	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/wbs"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 )
 
 func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.Listing, error) {
 	formData := buildFormData()
-	headers := base.HeaderGenerator.GenerateGeneralRequestHeaders("https://www.howoge.de", "https://www.howoge.de", true, false)
+	headers := base.HeaderGenerator.GenerateGeneralRequestHeaders(config.HowogeURL, "https://www.howoge.de", "https://www.howoge.de", true, false)
 
 	resp, err := base.HTTPClient.Post(ctx, config.HowogeURL, formData, headers)
 	if err != nil {
@@ -32,20 +35,41 @@ func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.List
 		if !ok {
 			log.Println("[Howoge] Error extracting zip", listing.Address)
 		}
+		details := strings.Join(listing.Features, " ") + " " + listing.Notice
+		floor, _ := common.ExtractFloor(details)
+		// the API only flags WBS flats, the level is mentioned in the features
+		var requirement wbs.Requirement
+		if listing.Wbs == "ja" {
+			requirement = wbs.Parse(details)
+			requirement.Required = true
+		}
 		listings = append(listings, common.Listing{
 			ID:          fmt.Sprintf("%d", listing.ID),
 			Company:     "Howoge",
+			Title:       listing.Address,
+			Description: strings.TrimSpace(details),
 			Price:       fmt.Sprintf("%.2f", listing.Rent),
+			RentType:    common.RentWarm, // "rent" is the Warmmiete shown on the site
 			Size:        fmt.Sprintf("%.2f", listing.Size),
 			Address:     listing.Address,
 			URL:         fmt.Sprintf("https://www.howoge.de%s", listing.Link),
 			ZipCode:     zip,
-			WbsRequired: listing.Wbs == "ja",
+			Wbs:         requirement,
+			Rooms:       formatRooms(listing.Rooms),
+			Floor:       floor,
+			Balcony:     common.HasBalcony(details),
 		})
 	}
 	return listings, nil
 }
 
+func formatRooms(rooms float64) string {
+	if rooms == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(rooms, 'f', -1, 64)
+}
+
 func buildFormData() map[string][]string {
 	formData := map[string][]string{
 		"tx_howrealestate_json_list[action]": {"immoList"},
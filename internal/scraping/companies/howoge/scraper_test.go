@@ -3,6 +3,7 @@ package howoge
 import (
 	"apartmenthunter/internal/config"
 	"apartmenthunter/internal/http"
+	"apartmenthunter/internal/http/replay"
 	"apartmenthunter/internal/scraping/common"
 	"apartmenthunter/internal/store"
 	"context"
@@ -14,9 +15,7 @@ import (
 
 // TestHowogeEndpoint_Reachability tests if the Howoge endpoint is accessible
 func TestHowogeEndpoint_Reachability(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	client := http.NewClient(10 * time.Second)
 	headers := map[string]string{
@@ -39,9 +38,7 @@ func TestHowogeEndpoint_Reachability(t *testing.T) {
 
 // TestHowogeScraper_RealEndpoint tests against the actual Howoge API endpoint
 func TestHowogeScraper_RealEndpoint(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	// Create real dependencies
 	httpClient := http.NewClient(30 * time.Second)
@@ -62,9 +59,7 @@ func TestHowogeScraper_RealEndpoint(t *testing.T) {
 
 // TestHowogeScraper_JSONStructure validates the JSON API structure we depend on
 func TestHowogeScraper_JSONStructure(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	httpClient := http.NewClient(30 * time.Second)
 	formData := buildFormData()
@@ -108,9 +103,7 @@ func TestHowogeScraper_JSONStructure(t *testing.T) {
 
 // TestHowogeScraper_JSONFields validates that required JSON fields exist when listings are present
 func TestHowogeScraper_JSONFields(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	httpClient := http.NewClient(30 * time.Second)
 	formData := buildFormData()
@@ -213,7 +206,7 @@ func TestHowogeFormData_Validation(t *testing.T) {
 	requiredFields := map[string]string{
 		"tx_howrealestate_json_list[action]": "immoList",
 		"tx_howrealestate_json_list[page]":   "1",
-		"tx_howrealestate_json_list[limit]":  "50",
+		"tx_howrealestate_json_list[limit]":  "100",
 		"tx_howrealestate_json_list[lang]":   "", // Empty is expected
 	}
 
@@ -230,3 +223,16 @@ func TestHowogeFormData_Validation(t *testing.T) {
 
 	t.Logf("Form data validation passed with %d fields", len(formData))
 }
+
+// TestHowogeScraper_Replay tests parsing of recorded responses against the golden listings,
+// run with -refresh to record them again
+func TestHowogeScraper_Replay(t *testing.T) {
+	client := replay.Client(t, "testdata/fixtures.json", http.NewClient(30*time.Second))
+	scraper := common.NewBaseScraper(client, store.NewScraperState(), "Howoge", FetchListings)
+
+	listings, err := scraper.Scrape(context.Background())
+	if err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	replay.Golden(t, "testdata/listings.golden", replay.Lines(listings))
+}
@@ -1,13 +1,15 @@
 package howoge
 
 type HowogeListing struct {
-	ID      int     `json:"uid"`
-	Address string  `json:"title"`
-	Rent    float64 `json:"rent"`
-	Size    float64 `json:"area"`
-	Wbs     string  `json:"wbs"`
-	Link    string  `json:"link"`
-	Notice  string  `json:"notice"`
+	ID       int      `json:"uid"`
+	Address  string   `json:"title"`
+	Rent     float64  `json:"rent"`
+	Size     float64  `json:"area"`
+	Rooms    float64  `json:"rooms"`
+	Wbs      string   `json:"wbs"`
+	Link     string   `json:"link"`
+	Notice   string   `json:"notice"`
+	Features []string `json:"features"`
 }
 
 // HowogeResponse Struct for API response
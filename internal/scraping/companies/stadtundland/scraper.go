@@ -3,6 +3,7 @@ package stadtundland
 import (
 	"apartmenthunter/internal/config"
 	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/wbs"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -12,7 +13,7 @@ import (
 
 func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.Listing, error) {
 	formData := buildFormData()
-	headers := base.HeaderGenerator.GenerateGeneralRequestHeaders("", "", false, true)
+	headers := base.HeaderGenerator.GenerateGeneralRequestHeaders(config.StadtUndLandURL, "", "", false, true)
 
 	resp, err := base.HTTPClient.PostJSON(ctx, config.StadtUndLandURL, formData, headers)
 	if err != nil {
@@ -30,16 +31,23 @@ func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.List
 
 	var listings []common.Listing
 	for _, listing := range data.Listings {
+		rooms, _ := common.ExtractRooms(listing.Title)
+		floor, _ := common.ExtractFloor(listing.Title)
 		listings = append(listings, common.Listing{
-			ID:      listing.Details.Id,
-			Company: "Stadt Und Land",
-			Price:   listing.Costs.Rent,
-			Size:    listing.Details.Area,
+			ID:       listing.Details.Id,
+			Company:  "Stadt Und Land",
+			Title:    listing.Title,
+			Price:    listing.Costs.Rent,
+			RentType: common.RentWarm,
+			Size:     listing.Details.Area,
 			Address: fmt.Sprintf("%s %s, %s %s",
 				listing.Address.Street, listing.Address.HouseNumber, listing.Address.PostalCode, listing.Address.City),
-			URL:         fmt.Sprintf("https://stadtundland.de/wohnungssuche/%s", url.QueryEscape(listing.Details.Id)),
-			ZipCode:     listing.Address.PostalCode,
-			WbsRequired: common.FilterWBSString(listing.Title),
+			URL:     fmt.Sprintf("https://stadtundland.de/wohnungssuche/%s", url.QueryEscape(listing.Details.Id)),
+			ZipCode: listing.Address.PostalCode,
+			Wbs:     wbs.Parse(listing.Title),
+			Rooms:   rooms,
+			Floor:   floor,
+			Balcony: common.HasBalcony(listing.Title),
 		})
 	}
 	return listings, nil
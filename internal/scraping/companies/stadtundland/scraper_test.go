@@ -3,6 +3,7 @@ package stadtundland
 import (
 	"apartmenthunter/internal/config"
 	"apartmenthunter/internal/http"
+	"apartmenthunter/internal/http/replay"
 	"apartmenthunter/internal/scraping/common"
 	"apartmenthunter/internal/store"
 	"context"
@@ -13,9 +14,7 @@ import (
 
 // TestStadtUndLandEndpoint_Reachability tests if the Stadt und Land endpoint is accessible
 func TestStadtUndLandEndpoint_Reachability(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	client := http.NewClient(10 * time.Second)
 	headers := map[string]string{
@@ -38,9 +37,7 @@ func TestStadtUndLandEndpoint_Reachability(t *testing.T) {
 
 // TestStadtUndLandScraper_RealEndpoint tests against the actual Stadt und Land API endpoint
 func TestStadtUndLandScraper_RealEndpoint(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	// Create real dependencies
 	httpClient := http.NewClient(30 * time.Second)
@@ -61,9 +58,7 @@ func TestStadtUndLandScraper_RealEndpoint(t *testing.T) {
 
 // TestStadtUndLandScraper_JSONStructure validates the JSON API structure we depend on
 func TestStadtUndLandScraper_JSONStructure(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	httpClient := http.NewClient(30 * time.Second)
 	jsonData := buildFormData()
@@ -105,9 +100,7 @@ func TestStadtUndLandScraper_JSONStructure(t *testing.T) {
 
 // TestStadtUndLandScraper_JSONFields validates that required JSON fields exist when listings are present
 func TestStadtUndLandScraper_JSONFields(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	httpClient := http.NewClient(30 * time.Second)
 	jsonData := buildFormData()
@@ -188,3 +181,16 @@ func TestStadtUndLandScraper_JSONFields(t *testing.T) {
 		t.Logf("SUCCESS: All required JSON fields found in all %d listings", totalListings)
 	}
 }
+
+// TestStadtUndLandScraper_Replay tests parsing of recorded responses against the golden listings,
+// run with -refresh to record them again
+func TestStadtUndLandScraper_Replay(t *testing.T) {
+	client := replay.Client(t, "testdata/fixtures.json", http.NewClient(30*time.Second))
+	scraper := common.NewBaseScraper(client, store.NewScraperState(), "StadtUndLand", FetchListings)
+
+	listings, err := scraper.Scrape(context.Background())
+	if err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	replay.Golden(t, "testdata/listings.golden", replay.Lines(listings))
+}
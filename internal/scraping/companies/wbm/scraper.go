@@ -3,6 +3,7 @@ package wbm
 import (
 	"apartmenthunter/internal/config"
 	"apartmenthunter/internal/scraping/common"
+	"apartmenthunter/internal/wbs"
 	"bytes"
 	"context"
 	"fmt"
@@ -12,12 +13,15 @@ import (
 )
 
 func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.Listing, error) {
-	headers := base.HeaderGenerator.GenerateGeneralRequestHeaders("", "", false, false)
+	headers := base.HeaderGenerator.GenerateGeneralRequestHeaders(config.WbmURL, "", "", false, false)
 
 	resp, err := base.HTTPClient.Get(ctx, config.WbmURL, headers)
 	if err != nil {
 		return nil, fmt.Errorf("error making get request: %w", err)
 	}
+	if resp.NotModified {
+		return nil, common.ErrUnchanged
+	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, fmt.Errorf("HTTP error: status code %d", resp.StatusCode)
@@ -43,7 +47,11 @@ func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.List
 		title := strings.TrimSpace(s.Find("h2.imageTitle").Text())
 
 		cost := extractValue(s, "div.main-property-value.main-property-rent", " €")
+		// the label next to the value says whether it is the Warmmiete or Kaltmiete
+		rentType := common.DetectRentType(s.Find("div.main-property-value.main-property-rent").Parent().Text(), common.RentWarm)
 		size := extractValue(s, "div.main-property-value.main-property-size", " m²")
+		rooms := extractValue(s, "div.main-property-value.main-property-rooms", "")
+		floor, _ := common.ExtractFloor(title)
 
 		relLink, exists := s.Find("div.btn-holder a").Attr("href")
 		if !exists {
@@ -52,14 +60,19 @@ func FetchListings(ctx context.Context, base *common.BaseScraper) ([]common.List
 		listingLink := fmt.Sprintf("%s%s", "https://www.wbm.de", relLink)
 
 		listings = append(listings, common.Listing{
-			ID:          postID,
-			Company:     "WBM",
-			Price:       cost,
-			Size:        size,
-			Address:     address,
-			URL:         listingLink,
-			ZipCode:     zip,
-			WbsRequired: common.FilterWBSString(title), // todo this might not work here
+			ID:       postID,
+			Company:  "WBM",
+			Title:    title,
+			Price:    cost,
+			RentType: rentType,
+			Size:     size,
+			Address:  address,
+			URL:      listingLink,
+			ZipCode:  zip,
+			Wbs:      wbs.Parse(title), // todo this might not work here
+			Rooms:    rooms,
+			Floor:    floor,
+			Balcony:  common.HasBalcony(title),
 		})
 	})
 	return listings, nil
@@ -3,10 +3,13 @@ package wbm
 import (
 	"apartmenthunter/internal/config"
 	"apartmenthunter/internal/http"
+	"apartmenthunter/internal/http/mock"
+	"apartmenthunter/internal/http/replay"
 	"apartmenthunter/internal/scraping/common"
 	"apartmenthunter/internal/store"
 	"bytes"
 	"context"
+	"errors"
 	"github.com/PuerkitoBio/goquery"
 	"strings"
 	"testing"
@@ -15,9 +18,7 @@ import (
 
 // TestWBMEndpoint_Reachability tests if the WBM endpoint is accessible
 func TestWBMEndpoint_Reachability(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	client := http.NewClient(10 * time.Second)
 	headers := map[string]string{
@@ -40,9 +41,7 @@ func TestWBMEndpoint_Reachability(t *testing.T) {
 
 // TestWBMScraper_RealEndpoint tests against the actual WBM search endpoint
 func TestWBMScraper_RealEndpoint(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	// Create real dependencies
 	httpClient := http.NewClient(30 * time.Second)
@@ -63,9 +62,7 @@ func TestWBMScraper_RealEndpoint(t *testing.T) {
 
 // TestWBMScraper_HTMLStructure validates the HTML structure we depend on
 func TestWBMScraper_HTMLStructure(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	httpClient := http.NewClient(30 * time.Second)
 	headers := map[string]string{
@@ -103,9 +100,7 @@ func TestWBMScraper_HTMLStructure(t *testing.T) {
 
 // TestWBMScraper_CSSSelectors validates that our CSS selectors work when listings exist
 func TestWBMScraper_CSSSelectors(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
+	replay.SkipUnlessLive(t)
 
 	httpClient := http.NewClient(30 * time.Second)
 	headers := map[string]string{
@@ -198,3 +193,29 @@ func TestWBMScraper_CSSSelectors(t *testing.T) {
 		t.Logf("SUCCESS: All required CSS selectors found in all %d items", totalItems)
 	}
 }
+
+// TestWBMScraper_Unchanged tests that an unchanged result page is not parsed
+func TestWBMScraper_Unchanged(t *testing.T) {
+	client := mock.NewHTTPClient()
+	client.GetFunc = func(ctx context.Context, url string, headers map[string]string) (*http.HTTPResponse, error) {
+		return &http.HTTPResponse{StatusCode: 200, Body: []byte("<html></html>"), NotModified: true}, nil
+	}
+	scraper := common.NewBaseScraper(client, store.NewScraperState(), "WBM", FetchListings)
+
+	if _, err := scraper.Scrape(context.Background()); !errors.Is(err, common.ErrUnchanged) {
+		t.Errorf("Scrape() error = %v, want ErrUnchanged", err)
+	}
+}
+
+// TestWBMScraper_Replay tests parsing of recorded responses against the golden listings,
+// run with -refresh to record them again
+func TestWBMScraper_Replay(t *testing.T) {
+	client := replay.Client(t, "testdata/fixtures.json", http.NewClient(30*time.Second))
+	scraper := common.NewBaseScraper(client, store.NewScraperState(), "WBM", FetchListings)
+
+	listings, err := scraper.Scrape(context.Background())
+	if err != nil {
+		t.Fatalf("Scrape() unexpected error: %v", err)
+	}
+	replay.Golden(t, "testdata/listings.golden", replay.Lines(listings))
+}
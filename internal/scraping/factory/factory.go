@@ -1,6 +1,8 @@
 package factory
 
 import (
+	"apartmenthunter/internal/bot"
+	"apartmenthunter/internal/geo"
 	"apartmenthunter/internal/http"
 	"apartmenthunter/internal/scraping/common"
 	"apartmenthunter/internal/scraping/companies/dewego"
@@ -9,6 +11,7 @@ import (
 	"apartmenthunter/internal/scraping/companies/stadtundland"
 	"apartmenthunter/internal/scraping/companies/wbm"
 	"apartmenthunter/internal/store"
+	"apartmenthunter/internal/transit"
 )
 
 type ScraperFactory interface {
@@ -18,27 +21,112 @@ type ScraperFactory interface {
 // DefaultScraperFactory creates scrapers with shared dependencies
 type DefaultScraperFactory struct {
 	httpClient http.HTTPClient
+	geocoder   *geo.Geocoder
+	commutes   *transit.Estimator
+
+	// per scraper clients derived from client, if set
+	client       *http.Client
+	landingPages map[string]string
+	proxies      *http.ProxyPool
+
+	wrap func(name string, client http.HTTPClient) http.HTTPClient
+}
+
+// Option configures optional dependencies of the factory
+type Option func(*DefaultScraperFactory)
+
+// WithGeocoder geocodes the listings of every created scraper
+func WithGeocoder(geocoder *geo.Geocoder) Option {
+	return func(f *DefaultScraperFactory) {
+		f.geocoder = geocoder
+	}
+}
+
+// WithCommuteEstimator estimates commutes for the listings of every created scraper
+func WithCommuteEstimator(estimator *transit.Estimator) Option {
+	return func(f *DefaultScraperFactory) {
+		f.commutes = estimator
+	}
 }
 
-func NewScraperFactory(httpClient http.HTTPClient) *DefaultScraperFactory {
-	return &DefaultScraperFactory{
+// WithSessions gives the scrapers listed in landingPages their own cookie session on
+// client, warmed up by visiting the given landing page
+func WithSessions(client *http.Client, landingPages map[string]string) Option {
+	return func(f *DefaultScraperFactory) {
+		f.client = client
+		f.landingPages = landingPages
+	}
+}
+
+// WithProxies assigns every created scraper a proxy of the pool to send its requests
+// through on client
+func WithProxies(client *http.Client, pool *http.ProxyPool) Option {
+	return func(f *DefaultScraperFactory) {
+		f.client = client
+		f.proxies = pool
+	}
+}
+
+// WithClientWrapper wraps the HTTP client of every created scraper, e.g. to observe its
+// responses
+func WithClientWrapper(wrap func(name string, client http.HTTPClient) http.HTTPClient) Option {
+	return func(f *DefaultScraperFactory) {
+		f.wrap = wrap
+	}
+}
+
+func NewScraperFactory(httpClient http.HTTPClient, opts ...Option) *DefaultScraperFactory {
+	f := &DefaultScraperFactory{
 		httpClient: httpClient,
 	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 func (f *DefaultScraperFactory) CreateScraper(scraperType string, state *store.ScraperState) common.Scraper {
 	switch scraperType {
 	case "Howoge":
-		return common.NewBaseScraper(f.httpClient, state, scraperType, howoge.FetchListings)
+		return f.newScraper(state, scraperType, howoge.FetchListings)
 	case "Dewego":
-		return common.NewBaseScraper(f.httpClient, state, scraperType, dewego.FetchListings)
+		return f.newScraper(state, scraperType, dewego.FetchListings)
 	case "Gewobag":
-		return common.NewBaseScraper(f.httpClient, state, scraperType, gewobag.FetchListings)
+		return f.newScraper(state, scraperType, gewobag.FetchListings)
 	case "StadtUndLand":
-		return common.NewBaseScraper(f.httpClient, state, scraperType, stadtundland.FetchListings)
+		return f.newScraper(state, scraperType, stadtundland.FetchListings)
 	case "WBM":
-		return common.NewBaseScraper(f.httpClient, state, scraperType, wbm.FetchListings)
+		return f.newScraper(state, scraperType, wbm.FetchListings)
 	default:
 		return nil
 	}
 }
+
+func (f *DefaultScraperFactory) newScraper(state *store.ScraperState, name string, scrapingFunc common.ScrapingFunc) *common.BaseScraper {
+	headers := bot.NewHeaderGenerator() // one browser per scraper, also for its session
+	client := f.clientFor(name, headers)
+	if f.wrap != nil {
+		client = f.wrap(name, client)
+	}
+	scraper := common.NewBaseScraper(client, state, name, scrapingFunc)
+	scraper.HeaderGenerator = headers
+	scraper.Geocoder = f.geocoder
+	scraper.Commutes = f.commutes
+	return scraper
+}
+
+// clientFor returns the HTTP client of a scraper, with its own proxy and session if
+// configured
+func (f *DefaultScraperFactory) clientFor(name string, headers *bot.HeaderGenerator) http.HTTPClient {
+	if f.client == nil {
+		return f.httpClient
+	}
+	client := f.client
+	if f.proxies != nil {
+		client = client.WithProxies(f.proxies)
+	}
+	if landingURL, ok := f.landingPages[name]; ok {
+		return client.NewSession(landingURL, headers.GenerateGeneralRequestHeaders(landingURL, "", "", false, false))
+	}
+	return client
+}
@@ -0,0 +1,105 @@
+// Package stats summarises how long listings stay online and when they appear, per company.
+package stats
+
+import (
+	"apartmenthunter/internal/store"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Company holds the lifetime statistics of one company's listings. Listings that were
+// already online when tracking started are left out, their first appearance is unknown.
+type Company struct {
+	Name         string
+	Listings     int           // listings that appeared while tracking
+	Removed      int           // of which have been taken offline
+	MedianOnline time.Duration // of the removed listings
+	ByWeekday    [7]int        // appearances per weekday, Sunday first
+	ByHour       [24]int       // appearances per hour of day
+}
+
+// Compute summarises the snapshots of one company, weekdays and hours are in loc
+func Compute(name string, snapshots []store.Snapshot, loc *time.Location) Company {
+	c := Company{Name: name}
+	var online []time.Duration
+	for _, snapshot := range snapshots {
+		if snapshot.Untracked {
+			continue
+		}
+		c.Listings++
+		first := snapshot.FirstSeen.In(loc)
+		c.ByWeekday[first.Weekday()]++
+		c.ByHour[first.Hour()]++
+		if !snapshot.Active {
+			c.Removed++
+			online = append(online, snapshot.Online())
+		}
+	}
+	c.MedianOnline = median(online)
+	return c
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	mid := len(durations) / 2
+	if len(durations)%2 == 0 {
+		return (durations[mid-1] + durations[mid]) / 2
+	}
+	return durations[mid]
+}
+
+// Text renders a plain text report, one block per company
+func Text(companies []Company) string {
+	if len(companies) == 0 {
+		return "no listings tracked yet\n"
+	}
+
+	var b strings.Builder
+	for _, c := range companies {
+		fmt.Fprintf(&b, "%s: %d listings, %d removed", c.Name, c.Listings, c.Removed)
+		if c.Removed > 0 {
+			fmt.Fprintf(&b, ", median online %s", formatDuration(c.MedianOnline))
+		}
+		b.WriteString("\n")
+		if c.Listings == 0 {
+			continue
+		}
+
+		b.WriteString("  weekday:")
+		for i := range c.ByWeekday {
+			day := time.Weekday((i + 1) % 7) // Monday first
+			fmt.Fprintf(&b, " %s %d", day.String()[:3], c.ByWeekday[day])
+		}
+		b.WriteString("\n  hour:   ")
+		for hour, count := range c.ByHour {
+			if count > 0 {
+				fmt.Fprintf(&b, " %02d:00 %d", hour, count)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// HTML renders the report for Telegram
+func HTML(companies []Company) string {
+	return "<b>Listing lifetimes</b>\n<pre>" + html.EscapeString(Text(companies)) + "</pre>"
+}
+
+// formatDuration rounds to minutes, or to hours for durations over a day
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < 24*time.Hour:
+		return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
@@ -0,0 +1,64 @@
+package stats
+
+import (
+	"apartmenthunter/internal/store"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCompute tests the lifetime statistics of a company
+func TestCompute(t *testing.T) {
+	monday := time.Date(2026, 10, 19, 9, 15, 0, 0, time.UTC)
+	snapshots := []store.Snapshot{
+		{ID: "0", FirstSeen: monday.Add(-time.Hour), LastSeen: monday, Untracked: true},
+		{ID: "1", FirstSeen: monday, LastSeen: monday.Add(40 * time.Minute)},
+		{ID: "2", FirstSeen: monday.Add(time.Hour), LastSeen: monday.Add(3 * time.Hour)},
+		{ID: "3", FirstSeen: monday.Add(24 * time.Hour), LastSeen: monday.Add(25 * time.Hour), Active: true},
+	}
+
+	c := Compute("Howoge", snapshots, time.UTC)
+	if c.Listings != 3 || c.Removed != 2 {
+		t.Errorf("Compute() counted %d listings and %d removed, want 3 and 2", c.Listings, c.Removed)
+	}
+	if c.MedianOnline != 80*time.Minute {
+		t.Errorf("MedianOnline = %v, want 1h20m", c.MedianOnline)
+	}
+	if c.ByWeekday[time.Monday] != 2 || c.ByWeekday[time.Tuesday] != 1 {
+		t.Errorf("ByWeekday = %v", c.ByWeekday)
+	}
+	if c.ByHour[9] != 2 || c.ByHour[10] != 1 || c.ByHour[8] != 0 {
+		t.Errorf("ByHour = %v", c.ByHour)
+	}
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	if c := Compute("Howoge", snapshots, berlin); c.ByHour[11] != 2 {
+		t.Errorf("ByHour in Berlin = %v, want hours in local time", c.ByHour)
+	}
+}
+
+// TestText tests the plain text report
+func TestText(t *testing.T) {
+	companies := []Company{
+		{Name: "Howoge", Listings: 2, Removed: 2, MedianOnline: 40 * time.Minute, ByWeekday: [7]int{1: 2}, ByHour: [24]int{9: 2}},
+		{Name: "WBM", Listings: 1, Removed: 1, MedianOnline: 26 * time.Hour, ByWeekday: [7]int{0: 1}, ByHour: [24]int{14: 1}},
+		{Name: "Gewobag"},
+	}
+	want := "Howoge: 2 listings, 2 removed, median online 40m\n" +
+		"  weekday: Mon 2 Tue 0 Wed 0 Thu 0 Fri 0 Sat 0 Sun 0\n" +
+		"  hour:    09:00 2\n" +
+		"WBM: 1 listings, 1 removed, median online 1d2h\n" +
+		"  weekday: Mon 0 Tue 0 Wed 0 Thu 0 Fri 0 Sat 0 Sun 1\n" +
+		"  hour:    14:00 1\n" +
+		"Gewobag: 0 listings, 0 removed\n"
+
+	if got := Text(companies); got != want {
+		t.Errorf("Text() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Text(nil); got != "no listings tracked yet\n" {
+		t.Errorf("Text(nil) = %q", got)
+	}
+	if got := HTML(companies[:1]); !strings.HasPrefix(got, "<b>Listing lifetimes</b>\n<pre>Howoge:") {
+		t.Errorf("HTML() = %q", got)
+	}
+}
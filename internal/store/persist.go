@@ -0,0 +1,60 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// stateFile is the JSON representation of a ScraperState on disk
+type stateFile struct {
+	Seen      []string   `json:"seen"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// Save writes the seen listings and their snapshots to path, replacing the file atomically
+func (s *ScraperState) Save(path string) error {
+	s.mu.RLock()
+	file := stateFile{Seen: make([]string, 0, len(s.listings))}
+	for postID := range s.listings {
+		file.Seen = append(file.Seen, postID)
+	}
+	s.mu.RUnlock()
+	file.Snapshots = s.Snapshots()
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadScraperState reads a state written by Save
+func LoadScraperState(path string) (*ScraperState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file stateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	s := NewScraperState()
+	for _, postID := range file.Seen {
+		s.listings[postID] = true
+	}
+	for i := range file.Snapshots {
+		snapshot := file.Snapshots[i]
+		s.snapshots[snapshot.ID] = &snapshot
+	}
+	return s, nil
+}
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Snapshot is the last known state of a listing
+type Snapshot struct {
+	ID        string    `json:"id"`
+	Price     string    `json:"price"`
+	Active    bool      `json:"active"` // false once the listing disappeared from the results
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	// Untracked listings were already online when tracking started, so their first
+	// appearance is unknown
+	Untracked bool `json:"untracked,omitempty"`
+	// Missed counts the scrapes in a row an active listing was missing from
+	Missed int `json:"missed,omitempty"`
+}
+
+// RemovalMisses is how many scrapes in a row a listing has to be missing from before it
+// counts as removed, so a partial result page does not remove and later relist listings
+const RemovalMisses = 3
+
+// Online returns how long the listing has been or was online as far as observed
+func (s Snapshot) Online() time.Duration {
+	return s.LastSeen.Sub(s.FirstSeen)
+}
+
+type EventType int
+
+const (
+	PriceChanged EventType = iota
+	Relisted
+	Removed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case PriceChanged:
+		return "price changed"
+	case Relisted:
+		return "relisted"
+	case Removed:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a change of an already seen listing
+type Event struct {
+	Type     EventType
+	ID       string
+	OldPrice string
+	NewPrice string
+}
+
+func (e Event) String() string {
+	if e.Type == PriceChanged {
+		return fmt.Sprintf("listing %s %s from %q to %q", e.ID, e.Type, e.OldPrice, e.NewPrice)
+	}
+	return fmt.Sprintf("listing %s %s", e.ID, e.Type)
+}
+
+// Observe compares the prices of all listings of a complete scrape, keyed by listing ID,
+// with the stored snapshots. It returns events for price changes, listings that came back
+// and listings that disappeared for RemovalMisses scrapes, ordered by ID. Listings
+// observed for the first time only get a snapshot; on the very first observation they are
+// marked as untracked. Empty scrapes are ignored, they are more likely a broken page than
+// every listing gone at once.
+func (s *ScraperState) Observe(prices map[string]string, now time.Time) []Event {
+	if len(prices) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	untracked := len(s.snapshots) == 0
+	var events []Event
+	for id, price := range prices {
+		snapshot, ok := s.snapshots[id]
+		if !ok {
+			s.snapshots[id] = &Snapshot{ID: id, Price: price, Active: true, FirstSeen: now, LastSeen: now, Untracked: untracked}
+			continue
+		}
+
+		switch {
+		case !snapshot.Active:
+			events = append(events, Event{Type: Relisted, ID: id, OldPrice: snapshot.Price, NewPrice: price})
+		case snapshot.Price != price:
+			events = append(events, Event{Type: PriceChanged, ID: id, OldPrice: snapshot.Price, NewPrice: price})
+		}
+		snapshot.Price, snapshot.Active, snapshot.LastSeen, snapshot.Missed = price, true, now, 0
+	}
+
+	for id, snapshot := range s.snapshots {
+		if _, ok := prices[id]; ok || !snapshot.Active {
+			continue
+		}
+		snapshot.Missed++
+		if snapshot.Missed >= RemovalMisses {
+			snapshot.Active, snapshot.Missed = false, 0
+			events = append(events, Event{Type: Removed, ID: id, OldPrice: snapshot.Price})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events
+}
+
+// Touch marks all listings still online as seen at now, for scrapes that were skipped
+// because nothing changed
+func (s *ScraperState) Touch(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, snapshot := range s.snapshots {
+		if snapshot.Active {
+			snapshot.LastSeen = now
+		}
+	}
+}
+
+// Snapshot returns the last known state of a listing
+func (s *ScraperState) Snapshot(postID string) (Snapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot, ok := s.snapshots[postID]
+	if !ok {
+		return Snapshot{}, false
+	}
+	return *snapshot, true
+}
+
+// Snapshots returns the state of all observed listings ordered by first appearance
+func (s *ScraperState) Snapshots() []Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshots := make([]Snapshot, 0, len(s.snapshots))
+	for _, snapshot := range s.snapshots {
+		snapshots = append(snapshots, *snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].FirstSeen.Equal(snapshots[j].FirstSeen) {
+			return snapshots[i].FirstSeen.Before(snapshots[j].FirstSeen)
+		}
+		return snapshots[i].ID < snapshots[j].ID
+	})
+	return snapshots
+}
+
+// Prune forgets removed listings last seen before the given time and returns how many
+func (s *ScraperState) Prune(before time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pruned := 0
+	for id, snapshot := range s.snapshots {
+		if !snapshot.Active && snapshot.LastSeen.Before(before) {
+			delete(s.snapshots, id)
+			delete(s.listings, id)
+			pruned++
+		}
+	}
+	return pruned
+}
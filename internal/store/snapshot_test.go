@@ -0,0 +1,196 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestObserve(t *testing.T) {
+	state := NewScraperState()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	if events := state.Observe(map[string]string{"1": "1050", "2": "800"}, now); len(events) != 0 {
+		t.Fatalf("first observation should not emit events, got %v", events)
+	}
+
+	steps := []struct {
+		name   string
+		prices map[string]string
+		want   []Event
+	}{
+		{
+			name:   "unchanged",
+			prices: map[string]string{"1": "1050", "2": "800"},
+		},
+		{
+			name:   "price drop and a missing listing",
+			prices: map[string]string{"1": "980"},
+			want: []Event{
+				{Type: PriceChanged, ID: "1", OldPrice: "1050", NewPrice: "980"},
+			},
+		},
+		{
+			name:   "still missing",
+			prices: map[string]string{"1": "980"},
+		},
+		{
+			name:   "removed after RemovalMisses scrapes",
+			prices: map[string]string{"1": "980"},
+			want: []Event{
+				{Type: Removed, ID: "2", OldPrice: "800"},
+			},
+		},
+		{
+			name:   "removed listing is reported once",
+			prices: map[string]string{"1": "980"},
+		},
+		{
+			name:   "relisted with new price and a new listing",
+			prices: map[string]string{"1": "980", "2": "850", "3": "700"},
+			want: []Event{
+				{Type: Relisted, ID: "2", OldPrice: "800", NewPrice: "850"},
+			},
+		},
+	}
+
+	for _, step := range steps {
+		now = now.Add(time.Minute)
+		events := state.Observe(step.prices, now)
+		if len(events) != len(step.want) {
+			t.Fatalf("%s: Observe() = %v, want %v", step.name, events, step.want)
+		}
+		for i := range events {
+			if events[i] != step.want[i] {
+				t.Errorf("%s: event %d = %v, want %v", step.name, i, events[i], step.want[i])
+			}
+		}
+	}
+
+	snapshot, ok := state.Snapshot("2")
+	if !ok || snapshot.Price != "850" || !snapshot.Active || !snapshot.LastSeen.Equal(now) {
+		t.Errorf("Snapshot(2) = %+v, %v", snapshot, ok)
+	}
+	if _, ok := state.Snapshot("unknown"); ok {
+		t.Error("Snapshot() of an unknown listing should not be found")
+	}
+}
+
+func TestObservePartialScrapes(t *testing.T) {
+	state := NewScraperState()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	full := map[string]string{"1": "800", "2": "900", "3": "700"}
+	state.Observe(full, now)
+
+	steps := []struct {
+		name   string
+		prices map[string]string
+	}{
+		{"empty", map[string]string{}},
+		{"full", full},
+		{"one page", map[string]string{"1": "800"}},
+		{"empty again", nil},
+		{"full again", full},
+	}
+	for _, step := range steps {
+		now = now.Add(time.Minute)
+		if events := state.Observe(step.prices, now); len(events) != 0 {
+			t.Errorf("%s: Observe() = %v, want no events", step.name, events)
+		}
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		if snapshot, _ := state.Snapshot(id); !snapshot.Active || snapshot.Missed != 0 {
+			t.Errorf("Snapshot(%s) = %+v, want active", id, snapshot)
+		}
+	}
+}
+
+func TestEventString(t *testing.T) {
+	tests := []struct {
+		event Event
+		want  string
+	}{
+		{Event{Type: PriceChanged, ID: "1", OldPrice: "1050", NewPrice: "980"}, `listing 1 price changed from "1050" to "980"`},
+		{Event{Type: Relisted, ID: "2"}, "listing 2 relisted"},
+		{Event{Type: Removed, ID: "3"}, "listing 3 removed"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.event.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestObserveLifetime(t *testing.T) {
+	state := NewScraperState()
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	state.Observe(map[string]string{"1": "800"}, start)
+	state.Observe(map[string]string{"1": "800", "2": "900"}, start.Add(10*time.Minute))
+	for _, minutes := range []time.Duration{30, 40, 50} {
+		state.Observe(map[string]string{"1": "800"}, start.Add(minutes*time.Minute))
+	}
+
+	first, _ := state.Snapshot("1")
+	if !first.Untracked {
+		t.Error("listing of the first observation should be untracked")
+	}
+	second, _ := state.Snapshot("2")
+	if second.Untracked || second.Active || second.Online() != 0 {
+		t.Errorf("Snapshot(2) = %+v, want a removed tracked listing", second)
+	}
+
+	state.Observe(map[string]string{"1": "800", "3": "700"}, start.Add(time.Hour))
+	snapshots := state.Snapshots()
+	if len(snapshots) != 3 || snapshots[0].ID != "1" || snapshots[2].ID != "3" {
+		t.Errorf("Snapshots() = %+v, want ordered by first appearance", snapshots)
+	}
+	if first, _ := state.Snapshot("1"); first.Online() != time.Hour {
+		t.Errorf("Online() = %v, want 1h", first.Online())
+	}
+
+	state.Touch(start.Add(2 * time.Hour))
+	if first, _ := state.Snapshot("1"); first.Online() != 2*time.Hour {
+		t.Errorf("Online() after Touch() = %v, want 2h", first.Online())
+	}
+	if second, _ := state.Snapshot("2"); second.Online() != 0 {
+		t.Errorf("Touch() should not extend removed listings, Online() = %v", second.Online())
+	}
+
+	if pruned := state.Prune(start.Add(11 * time.Minute)); pruned != 1 {
+		t.Errorf("Prune() = %d, want 1", pruned)
+	}
+	if _, ok := state.Snapshot("2"); ok {
+		t.Error("pruned listing should be forgotten")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "WBM.json")
+	state := NewScraperState()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	state.MarkAsSeen("1")
+	state.Observe(map[string]string{"1": "800"}, now)
+
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	loaded, err := LoadScraperState(path)
+	if err != nil {
+		t.Fatalf("LoadScraperState() unexpected error: %v", err)
+	}
+	if !loaded.Exists("1") {
+		t.Error("loaded state should contain the seen listing")
+	}
+	snapshot, ok := loaded.Snapshot("1")
+	if !ok || snapshot.Price != "800" || !snapshot.FirstSeen.Equal(now) || !snapshot.Untracked {
+		t.Errorf("loaded Snapshot(1) = %+v, %v", snapshot, ok)
+	}
+
+	if _, err := LoadScraperState(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadScraperState() of a missing file = %v, want ErrNotExist", err)
+	}
+}
@@ -14,13 +14,15 @@ type ScraperStore interface {
 }
 
 type ScraperState struct {
-	mu       sync.RWMutex
-	listings map[string]bool
+	mu        sync.RWMutex
+	listings  map[string]bool
+	snapshots map[string]*Snapshot
 }
 
 func NewScraperState() *ScraperState {
 	return &ScraperState{
-		listings: make(map[string]bool),
+		listings:  make(map[string]bool),
+		snapshots: make(map[string]*Snapshot),
 	}
 }
 
@@ -57,4 +59,5 @@ func (s *ScraperState) Clear() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.listings = make(map[string]bool)
+	s.snapshots = make(map[string]*Snapshot)
 }
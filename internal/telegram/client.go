@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,6 +18,12 @@ type Client struct {
 	HTTPClient *http.Client
 }
 
+// MessageOptions controls how a single message is delivered
+type MessageOptions struct {
+	ChatID              string // empty uses the default chat
+	DisableNotification bool   // deliver silently
+}
+
 // NewClient creates a new Telegram client
 func NewClient(baseURL, botToken, chatID string) (*Client, error) {
 	if baseURL == "" {
@@ -37,17 +44,32 @@ func NewClient(baseURL, botToken, chatID string) (*Client, error) {
 	}, nil
 }
 
-// SendMessage sends a raw HTML message
+// SendMessage sends a raw HTML message to the default chat
 func (c *Client) SendMessage(ctx context.Context, htmlMessage string) error {
+	return c.SendMessageTo(ctx, c.ChatID, htmlMessage)
+}
+
+// SendMessageTo sends a raw HTML message to the given chat, falling back to the default chat if empty
+func (c *Client) SendMessageTo(ctx context.Context, chatID, htmlMessage string) error {
+	return c.SendMessageWithOptions(ctx, htmlMessage, MessageOptions{ChatID: chatID})
+}
+
+// SendMessageWithOptions sends a raw HTML message using the given delivery options
+func (c *Client) SendMessageWithOptions(ctx context.Context, htmlMessage string, opts MessageOptions) error {
 	if c.HTTPClient == nil {
 		c.HTTPClient = http.DefaultClient
 	}
+	chatID := opts.ChatID
+	if chatID == "" {
+		chatID = c.ChatID
+	}
 	apiURL := fmt.Sprintf("%s/bot%s/sendMessage", c.BaseURL, c.BotToken)
 	formData := url.Values{
-		"chat_id":                  {c.ChatID},
+		"chat_id":                  {chatID},
 		"text":                     {htmlMessage},
 		"parse_mode":               {"HTML"},
 		"disable_web_page_preview": {"true"},
+		"disable_notification":     {strconv.FormatBool(opts.DisableNotification)},
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, strings.NewReader(formData.Encode()))
@@ -334,3 +334,94 @@ func TestClient_SendListing(t *testing.T) {
 		})
 	}
 }
+
+// TestClient_SendMessageTo tests that messages are routed to the requested chat
+func TestClient_SendMessageTo(t *testing.T) {
+	tests := []struct {
+		name       string
+		chatID     string
+		wantChatID string
+	}{
+		{
+			name:       "explicit chat id",
+			chatID:     "other-chat",
+			wantChatID: "other-chat",
+		},
+		{
+			name:       "empty chat id falls back to default",
+			chatID:     "",
+			wantChatID: "test-chat-id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotChatID string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Errorf("failed to parse form: %v", err)
+				}
+				gotChatID = r.PostForm.Get("chat_id")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, _ := createTestClient(server.URL)
+			if err := client.SendMessageTo(context.Background(), tt.chatID, "test message"); err != nil {
+				t.Fatalf("SendMessageTo() unexpected error: %v", err)
+			}
+			if gotChatID != tt.wantChatID {
+				t.Errorf("chat_id = %q, want %q", gotChatID, tt.wantChatID)
+			}
+		})
+	}
+}
+
+// TestClient_SendMessageWithOptions tests that delivery options are passed to telegram
+func TestClient_SendMessageWithOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       MessageOptions
+		wantChatID string
+		wantSilent string
+	}{
+		{
+			name:       "default options",
+			opts:       MessageOptions{},
+			wantChatID: "test-chat-id",
+			wantSilent: "false",
+		},
+		{
+			name:       "silent message to other chat",
+			opts:       MessageOptions{ChatID: "other-chat", DisableNotification: true},
+			wantChatID: "other-chat",
+			wantSilent: "true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotChatID, gotSilent string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Errorf("failed to parse form: %v", err)
+				}
+				gotChatID = r.PostForm.Get("chat_id")
+				gotSilent = r.PostForm.Get("disable_notification")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, _ := createTestClient(server.URL)
+			if err := client.SendMessageWithOptions(context.Background(), "test message", tt.opts); err != nil {
+				t.Fatalf("SendMessageWithOptions() unexpected error: %v", err)
+			}
+			if gotChatID != tt.wantChatID {
+				t.Errorf("chat_id = %q, want %q", gotChatID, tt.wantChatID)
+			}
+			if gotSilent != tt.wantSilent {
+				t.Errorf("disable_notification = %q, want %q", gotSilent, tt.wantSilent)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pollTimeout is the long polling timeout for getUpdates, it has to stay below the
+// timeout of the HTTP client
+const pollTimeout = 4 * time.Second
+
+// Update is an incoming update from the Bot API, only messages are used
+type Update struct {
+	ID      int      `json:"update_id"`
+	Message *Message `json:"message"`
+}
+
+// Message is a text message sent to the bot
+type Message struct {
+	Text string `json:"text"`
+	Chat struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+}
+
+// CommandHandler answers a bot command with an HTML message, args is the text after the command
+type CommandHandler func(ctx context.Context, args string) string
+
+// GetUpdates fetches updates starting at offset, waiting up to timeout for new ones
+func (c *Client) GetUpdates(ctx context.Context, offset int, timeout time.Duration) ([]Update, error) {
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+	query := url.Values{
+		"offset":          {strconv.Itoa(offset)},
+		"timeout":         {strconv.Itoa(int(timeout.Seconds()))},
+		"allowed_updates": {`["message"]`},
+	}
+	apiURL := fmt.Sprintf("%s/bot%s/getUpdates?%s", c.BaseURL, c.BotToken, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("telegram: unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		OK     bool     `json:"ok"`
+		Result []Update `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("telegram: decoding updates: %w", err)
+	}
+	if !body.OK {
+		return nil, fmt.Errorf("telegram: getUpdates failed")
+	}
+	return body.Result, nil
+}
+
+// ListenCommands polls for messages and answers commands like "/stats" in the chat they
+// were sent from, until ctx is cancelled. Only chats in allowedChats are answered, unknown
+// commands and other messages are ignored.
+func (c *Client) ListenCommands(ctx context.Context, allowedChats []string, handlers map[string]CommandHandler) {
+	allowed := make(map[string]bool, len(allowedChats))
+	for _, chatID := range allowedChats {
+		allowed[chatID] = true
+	}
+
+	offset := 0
+	for ctx.Err() == nil {
+		updates, err := c.GetUpdates(ctx, offset, pollTimeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("telegram: polling commands: %v", err)
+				sleep(ctx, 10*time.Second)
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.ID + 1
+			if update.Message == nil {
+				continue
+			}
+			command, args := ParseCommand(update.Message.Text)
+			handler, ok := handlers[command]
+			if !ok {
+				continue
+			}
+			chatID := strconv.FormatInt(update.Message.Chat.ID, 10)
+			if !allowed[chatID] {
+				log.Printf("telegram: ignoring /%s from unknown chat %s", command, chatID)
+				continue
+			}
+			if err := c.SendMessageTo(ctx, chatID, handler(ctx, args)); err != nil {
+				log.Printf("telegram: answering %s: %v", command, err)
+			}
+		}
+	}
+}
+
+// ParseCommand splits "/stats@MyBot args" into "stats" and "args", the command is empty
+// if the text is not a command
+func ParseCommand(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", ""
+	}
+	command, args, _ := strings.Cut(text[1:], " ")
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), strings.TrimSpace(args)
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestParseCommand tests splitting bot commands from their arguments
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text        string
+		wantCommand string
+		wantArgs    string
+	}{
+		{"/stats", "stats", ""},
+		{"/Stats@ApartmentSharkBot Howoge", "stats", "Howoge"},
+		{"  /stats  WBM ", "stats", "WBM"},
+		{"stats", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			command, args := ParseCommand(tt.text)
+			if command != tt.wantCommand || args != tt.wantArgs {
+				t.Errorf("ParseCommand(%q) = %q, %q, want %q, %q", tt.text, command, args, tt.wantCommand, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// TestListenCommands tests answering commands received via getUpdates
+func TestListenCommands(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var offsets []string
+	var replies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+			offsets = append(offsets, r.URL.Query().Get("offset"))
+			if len(offsets) == 1 {
+				fmt.Fprint(w, `{"ok":true,"result":[
+					{"update_id":7,"message":{"text":"hello","chat":{"id":42}}},
+					{"update_id":8,"message":{"text":"/stats WBM","chat":{"id":42}}},
+					{"update_id":9,"message":{"text":"/stats","chat":{"id":666}}}]}`)
+				return
+			}
+			cancel()
+			fmt.Fprint(w, `{"ok":true,"result":[]}`)
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			_ = r.ParseForm()
+			replies = append(replies, r.Form.Get("chat_id")+": "+r.Form.Get("text"))
+		}
+	}))
+	defer server.Close()
+
+	client, _ := createTestClient(server.URL)
+	client.ListenCommands(ctx, []string{"42"}, map[string]CommandHandler{
+		"stats": func(_ context.Context, args string) string { return "stats for " + args },
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(replies) != 1 || replies[0] != "42: stats for WBM" {
+		t.Errorf("replies = %v, want a single answer to /stats from the allowed chat", replies)
+	}
+	if len(offsets) < 2 || offsets[0] != "0" || offsets[1] != "10" {
+		t.Errorf("offsets = %v, want polling to continue after the last update", offsets)
+	}
+}
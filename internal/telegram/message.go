@@ -2,10 +2,15 @@ package telegram
 
 import (
 	"fmt"
+	"strings"
 )
 
 type TelegramInfo struct {
 	Address, Size, Rent, MapLink, ListingLink, Site string
+
+	Label string   // optional prefix of the header, e.g. "Near match"
+	Score string   // optional match score, e.g. "82/100"
+	Notes []string // optional lines shown below the listing details
 }
 
 func BuildHTML(info *TelegramInfo) string {
@@ -28,7 +33,18 @@ func BuildHTML(info *TelegramInfo) string {
 		rent = "-"
 	}
 
-	site := info.Site
+	header := fmt.Sprintf("%s Listing", info.Site)
+	if info.Label != "" {
+		header = fmt.Sprintf("%s: %s", info.Label, header)
+	}
+
+	notes := ""
+	if info.Score != "" {
+		notes += fmt.Sprintf("\n<b>Score:</b> %s", info.Score)
+	}
+	for _, note := range info.Notes {
+		notes += fmt.Sprintf("\n<i>%s</i>", note)
+	}
 
 	mapLink := info.MapLink
 	if mapLink == "" {
@@ -40,14 +56,24 @@ func BuildHTML(info *TelegramInfo) string {
 		listingLink = "#"
 	}
 
-	return fmt.Sprintf(`<b>%s Listing</b>
+	return fmt.Sprintf(`<b>%s</b>
 
 <b>Address:</b> %s
 <b>Size:</b> %s m²
-<b>Rent:</b> %s €
+<b>Rent:</b> %s €%s
 
 <a href="%s">View Map</a>
 <a href="%s">View Listing</a>`,
-		site, address, size, rent, mapLink, listingLink,
+		header, address, size, rent, notes, mapLink, listingLink,
 	)
 }
+
+// BuildBatchHTML combines several listings under a common header into one message
+func BuildBatchHTML(header string, infos []*TelegramInfo) string {
+	parts := make([]string, 0, len(infos)+1)
+	parts = append(parts, fmt.Sprintf("<b>%s</b>", header))
+	for _, info := range infos {
+		parts = append(parts, BuildHTML(info))
+	}
+	return strings.Join(parts, "\n\n")
+}
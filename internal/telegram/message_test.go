@@ -81,12 +81,12 @@ func TestBuildHTML_Fallbacks(t *testing.T) {
 			},
 			expected: `<b> Listing</b>
 
-  <b>Address:</b> -
-  <b>Size:</b> - m²
-  <b>Rent:</b> - €
+<b>Address:</b> -
+<b>Size:</b> - m²
+<b>Rent:</b> - €
 
-  <a href="#">View Map</a>
-  <a href="#">View Listing</a>`,
+<a href="#">View Map</a>
+<a href="#">View Listing</a>`,
 		},
 		{
 			name: "partial data with fallbacks",
@@ -100,12 +100,12 @@ func TestBuildHTML_Fallbacks(t *testing.T) {
 			},
 			expected: `<b> Listing</b>
 
-  <b>Address:</b> Test Street 123
-  <b>Size:</b> - m²
-  <b>Rent:</b> 800 €
+<b>Address:</b> Test Street 123
+<b>Size:</b> - m²
+<b>Rent:</b> 800 €
 
-  <a href="#">View Map</a>
-  <a href="https://example.com">View Listing</a>`,
+<a href="#">View Map</a>
+<a href="https://example.com">View Listing</a>`,
 		},
 	}
 
@@ -134,3 +134,55 @@ func BenchmarkBuildHTML(b *testing.B) {
 		BuildHTML(info)
 	}
 }
+
+func TestBuildBatchHTML(t *testing.T) {
+	infos := []*TelegramInfo{
+		{Address: "First", Site: "Howoge"},
+		{Address: "Second", Site: "WBM"},
+	}
+	result := BuildBatchHTML("2 listings during quiet hours", infos)
+
+	if !strings.HasPrefix(result, "<b>2 listings during quiet hours</b>\n\n") {
+		t.Errorf("BuildBatchHTML() should start with the header, got %s", result)
+	}
+	for _, info := range infos {
+		if !strings.Contains(result, BuildHTML(info)) {
+			t.Errorf("BuildBatchHTML() should contain listing %s", info.Address)
+		}
+	}
+	if strings.Index(result, "First") > strings.Index(result, "Second") {
+		t.Errorf("BuildBatchHTML() should keep the listing order")
+	}
+}
+
+func TestBuildHTML_LabelAndNotes(t *testing.T) {
+	info := &TelegramInfo{
+		Address: "Test",
+		Size:    "38",
+		Rent:    "1010",
+		Site:    "Howoge",
+		Label:   "Near match",
+		Notes:   []string{"rent 1010 € is 10 € (1.0%) over your max of 1000 €", "size 38 m² is 2 m² below your min of 40 m²"},
+	}
+	result := BuildHTML(info)
+
+	if !strings.HasPrefix(result, "<b>Near match: Howoge Listing</b>") {
+		t.Errorf("BuildHTML() header should carry the label, got %s", result)
+	}
+	expected := "<b>Rent:</b> 1010 €\n<i>rent 1010 € is 10 € (1.0%) over your max of 1000 €</i>\n<i>size 38 m² is 2 m² below your min of 40 m²</i>\n\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("BuildHTML() notes should follow the rent, got %s", result)
+	}
+}
+
+func TestBuildHTML_Score(t *testing.T) {
+	info := &TelegramInfo{Address: "Test", Size: "50", Rent: "800", Site: "WBM", Score: "82/100"}
+	result := BuildHTML(info)
+
+	if !strings.Contains(result, "<b>Rent:</b> 800 €\n<b>Score:</b> 82/100\n\n") {
+		t.Errorf("BuildHTML() should show the score below the rent, got %s", result)
+	}
+	if strings.Contains(BuildHTML(&TelegramInfo{Site: "WBM"}), "Score") {
+		t.Error("BuildHTML() should omit the score when not set")
+	}
+}
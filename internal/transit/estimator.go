@@ -0,0 +1,51 @@
+package transit
+
+import (
+	"apartmenthunter/internal/geo"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Estimator computes commute times from listings to a fixed set of destinations and
+// caches them per origin, as scrapers return the same listings on every run
+type Estimator struct {
+	router       *Router
+	departure    int
+	destinations []geo.Point
+
+	mu    sync.Mutex
+	cache map[geo.Point]map[geo.Point]int
+}
+
+// NewEstimator creates an estimator for commutes leaving at departure ("HH:MM")
+func NewEstimator(router *Router, departure string, destinations []geo.Point) (*Estimator, error) {
+	t, err := time.Parse("15:04", departure)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commute departure %q: %w", departure, err)
+	}
+	return &Estimator{
+		router:       router,
+		departure:    t.Hour()*3600 + t.Minute()*60,
+		destinations: destinations,
+		cache:        map[geo.Point]map[geo.Point]int{},
+	}, nil
+}
+
+// Estimate returns the travel minutes from the origin to every reachable destination
+func (e *Estimator) Estimate(from geo.Point) map[geo.Point]int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if minutes, ok := e.cache[from]; ok {
+		return minutes
+	}
+	minutes := make(map[geo.Point]int, len(e.destinations))
+	for _, to := range e.destinations {
+		if m, ok := e.router.Travel(from, to, e.departure); ok {
+			minutes[to] = m
+		}
+	}
+	e.cache[from] = minutes
+	return minutes
+}
@@ -0,0 +1,200 @@
+// Package transit estimates public transport travel times offline from a GTFS feed,
+// e.g. the VBB feed for Berlin and Brandenburg.
+package transit
+
+import (
+	"apartmenthunter/internal/geo"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stop is a GTFS stop or platform
+type Stop struct {
+	ID    string
+	Name  string
+	Point geo.Point
+}
+
+// connection is one vehicle ride between two consecutive stops of a trip
+type connection struct {
+	from, to int32
+	dep, arr int32 // seconds since midnight, may exceed 24h for trips after midnight
+	trip     int32
+}
+
+// Feed holds the stops and the connections of one service day, sorted by departure
+type Feed struct {
+	Stops       []Stop
+	connections []connection
+}
+
+// LoadGTFS reads stops.txt, trips.txt, stop_times.txt and, if present, calendar.txt from
+// an unpacked GTFS feed. Only trips whose service runs on the given weekday are kept;
+// calendar date ranges and exceptions are ignored since the result is an estimate.
+func LoadGTFS(dir string, weekday time.Weekday) (*Feed, error) {
+	feed := &Feed{}
+	stopIndex := map[string]int32{}
+	err := readCSV(filepath.Join(dir, "stops.txt"), func(row map[string]string) error {
+		lat, errLat := strconv.ParseFloat(row["stop_lat"], 64)
+		lon, errLon := strconv.ParseFloat(row["stop_lon"], 64)
+		if errLat != nil || errLon != nil {
+			return fmt.Errorf("stop %s: invalid coordinates", row["stop_id"])
+		}
+		stopIndex[row["stop_id"]] = int32(len(feed.Stops))
+		feed.Stops = append(feed.Stops, Stop{ID: row["stop_id"], Name: row["stop_name"], Point: geo.Point{Lat: lat, Lon: lon}})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := loadServices(filepath.Join(dir, "calendar.txt"), weekday)
+	if err != nil {
+		return nil, err
+	}
+
+	tripIndex := map[string]int32{}
+	err = readCSV(filepath.Join(dir, "trips.txt"), func(row map[string]string) error {
+		if services == nil || services[row["service_id"]] {
+			tripIndex[row["trip_id"]] = int32(len(tripIndex))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type stopTime struct {
+		stop     int32
+		sequence int
+		arr, dep int32
+	}
+	stopTimes := make([][]stopTime, len(tripIndex))
+	err = readCSV(filepath.Join(dir, "stop_times.txt"), func(row map[string]string) error {
+		trip, ok := tripIndex[row["trip_id"]]
+		if !ok {
+			return nil // service does not run on the weekday
+		}
+		stop, ok := stopIndex[row["stop_id"]]
+		if !ok {
+			return fmt.Errorf("trip %s: unknown stop %s", row["trip_id"], row["stop_id"])
+		}
+		sequence, err := strconv.Atoi(row["stop_sequence"])
+		if err != nil {
+			return fmt.Errorf("trip %s: invalid stop_sequence %q", row["trip_id"], row["stop_sequence"])
+		}
+		arr, errArr := parseGTFSTime(row["arrival_time"])
+		dep, errDep := parseGTFSTime(row["departure_time"])
+		if errArr != nil || errDep != nil {
+			return fmt.Errorf("trip %s: invalid time at stop %s", row["trip_id"], row["stop_id"])
+		}
+		stopTimes[trip] = append(stopTimes[trip], stopTime{stop: stop, sequence: sequence, arr: arr, dep: dep})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for trip, times := range stopTimes {
+		sort.Slice(times, func(i, j int) bool { return times[i].sequence < times[j].sequence })
+		for i := 1; i < len(times); i++ {
+			feed.connections = append(feed.connections, connection{
+				from: times[i-1].stop,
+				to:   times[i].stop,
+				dep:  times[i-1].dep,
+				arr:  times[i].arr,
+				trip: int32(trip),
+			})
+		}
+	}
+	sort.Slice(feed.connections, func(i, j int) bool { return feed.connections[i].dep < feed.connections[j].dep })
+	return feed, nil
+}
+
+// Connections returns the number of connections in the feed
+func (f *Feed) Connections() int {
+	return len(f.connections)
+}
+
+// loadServices returns the service IDs running on the weekday, nil if the feed has no
+// calendar.txt and every trip should be kept
+func loadServices(path string, weekday time.Weekday) (map[string]bool, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	column := strings.ToLower(weekday.String())
+	services := map[string]bool{}
+	err := readCSV(path, func(row map[string]string) error {
+		if row[column] == "1" {
+			services[row["service_id"]] = true
+		}
+		return nil
+	})
+	return services, err
+}
+
+// readCSV calls fn for every row of a GTFS file with the values keyed by column name
+func readCSV(path string, fn func(row map[string]string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("reading %s header: %w", filepath.Base(path), err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	row := make(map[string]string, len(header))
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+		}
+		for i, name := range header {
+			if i < len(record) {
+				row[name] = strings.TrimSpace(record[i])
+			} else {
+				row[name] = ""
+			}
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+	}
+}
+
+// parseGTFSTime parses "H:MM:SS" into seconds since midnight, hours may exceed 23
+func parseGTFSTime(s string) (int32, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, strconv.ErrSyntax
+	}
+	var seconds int32
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, strconv.ErrSyntax
+		}
+		seconds = seconds*60 + int32(n)
+	}
+	return seconds, nil
+}
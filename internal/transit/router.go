@@ -0,0 +1,136 @@
+package transit
+
+import (
+	"apartmenthunter/internal/geo"
+	"math"
+	"sort"
+)
+
+const (
+	walkSpeedKmh   = 4.5
+	walkDetour     = 1.3  // streets are longer than the straight line
+	maxWalkKm      = 1.0  // to or from a stop
+	transferKm     = 0.25 // between stops when changing lines
+	maxTripSeconds = 2 * 3600
+	cellDegrees    = 0.01
+)
+
+type footpath struct {
+	to       int32
+	duration int32
+}
+
+type cell struct {
+	lat, lon int
+}
+
+// Router finds earliest arrivals with the connection scan algorithm
+type Router struct {
+	feed      *Feed
+	grid      map[cell][]int32 // stops by grid cell
+	footpaths [][]footpath     // walking transfers between nearby stops
+}
+
+// NewRouter indexes the stops of a feed and precomputes walking transfers
+func NewRouter(feed *Feed) *Router {
+	r := &Router{
+		feed:      feed,
+		grid:      map[cell][]int32{},
+		footpaths: make([][]footpath, len(feed.Stops)),
+	}
+	for i, stop := range feed.Stops {
+		c := cellOf(stop.Point)
+		r.grid[c] = append(r.grid[c], int32(i))
+	}
+	for i, stop := range feed.Stops {
+		for _, j := range r.stopsNear(stop.Point, transferKm) {
+			if j != int32(i) {
+				r.footpaths[i] = append(r.footpaths[i], footpath{to: j, duration: walkSeconds(stop.Point, feed.Stops[j].Point)})
+			}
+		}
+	}
+	return r
+}
+
+// Travel returns the travel time in minutes from one point to another leaving at the
+// given time of day in seconds, including walking to and from the stops. Returns false
+// if the destination cannot be reached within two hours.
+func (r *Router) Travel(from, to geo.Point, departure int) (int, bool) {
+	best := math.MaxInt32
+	if geo.DistanceKm(from, to) <= maxWalkKm {
+		best = departure + int(walkSeconds(from, to))
+	}
+
+	earliest := make([]int32, len(r.feed.Stops))
+	for i := range earliest {
+		earliest[i] = math.MaxInt32
+	}
+	for _, stop := range r.stopsNear(from, maxWalkKm) {
+		earliest[stop] = int32(departure) + walkSeconds(from, r.feed.Stops[stop].Point)
+	}
+
+	connections := r.feed.connections
+	start := sort.Search(len(connections), func(i int) bool { return connections[i].dep >= int32(departure) })
+	boarded := map[int32]bool{}
+	for _, c := range connections[start:] {
+		if c.dep > int32(departure+maxTripSeconds) {
+			break
+		}
+		if !boarded[c.trip] {
+			if earliest[c.from] > c.dep {
+				continue
+			}
+			boarded[c.trip] = true
+		}
+		if c.arr < earliest[c.to] {
+			earliest[c.to] = c.arr
+			for _, fp := range r.footpaths[c.to] {
+				if arr := c.arr + fp.duration; arr < earliest[fp.to] {
+					earliest[fp.to] = arr
+				}
+			}
+		}
+	}
+
+	for _, stop := range r.stopsNear(to, maxWalkKm) {
+		if earliest[stop] == math.MaxInt32 {
+			continue
+		}
+		if arr := int(earliest[stop]) + int(walkSeconds(r.feed.Stops[stop].Point, to)); arr < best {
+			best = arr
+		}
+	}
+
+	if best == math.MaxInt32 || best-departure > maxTripSeconds {
+		return 0, false
+	}
+	return int(math.Ceil(float64(best-departure) / 60)), true
+}
+
+// stopsNear returns the stops within km of the point
+func (r *Router) stopsNear(p geo.Point, km float64) []int32 {
+	dLat := km / 111.0
+	dLon := km / (111.0 * math.Cos(p.Lat*math.Pi/180))
+	minCell := cellOf(geo.Point{Lat: p.Lat - dLat, Lon: p.Lon - dLon})
+	maxCell := cellOf(geo.Point{Lat: p.Lat + dLat, Lon: p.Lon + dLon})
+
+	var stops []int32
+	for lat := minCell.lat; lat <= maxCell.lat; lat++ {
+		for lon := minCell.lon; lon <= maxCell.lon; lon++ {
+			for _, stop := range r.grid[cell{lat, lon}] {
+				if geo.DistanceKm(p, r.feed.Stops[stop].Point) <= km {
+					stops = append(stops, stop)
+				}
+			}
+		}
+	}
+	return stops
+}
+
+func cellOf(p geo.Point) cell {
+	return cell{lat: int(math.Floor(p.Lat / cellDegrees)), lon: int(math.Floor(p.Lon / cellDegrees))}
+}
+
+func walkSeconds(a, b geo.Point) int32 {
+	return int32(geo.DistanceKm(a, b) * walkDetour / walkSpeedKmh * 3600)
+}
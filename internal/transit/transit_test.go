@@ -0,0 +1,159 @@
+package transit
+
+import (
+	"apartmenthunter/internal/geo"
+	"testing"
+	"time"
+)
+
+var (
+	stationA = geo.Point{Lat: 52.5000, Lon: 13.3000}
+	stationC = geo.Point{Lat: 52.5000, Lon: 13.5000}
+)
+
+func loadTestRouter(t *testing.T, weekday time.Weekday) *Router {
+	t.Helper()
+	feed, err := LoadGTFS("testdata/gtfs", weekday)
+	if err != nil {
+		t.Fatalf("LoadGTFS() error = %v", err)
+	}
+	return NewRouter(feed)
+}
+
+// TestLoadGTFS tests that only trips running on the weekday are loaded
+func TestLoadGTFS(t *testing.T) {
+	tests := []struct {
+		weekday     time.Weekday
+		connections int
+	}{
+		{time.Tuesday, 3},
+		{time.Sunday, 1},
+		{time.Saturday, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.weekday.String(), func(t *testing.T) {
+			feed, err := LoadGTFS("testdata/gtfs", tt.weekday)
+			if err != nil {
+				t.Fatalf("LoadGTFS() error = %v", err)
+			}
+			if len(feed.Stops) != 4 {
+				t.Errorf("len(Stops) = %d, want 4", len(feed.Stops))
+			}
+			if feed.Connections() != tt.connections {
+				t.Errorf("Connections() = %d, want %d", feed.Connections(), tt.connections)
+			}
+		})
+	}
+
+	if _, err := LoadGTFS("testdata/missing", time.Tuesday); err == nil {
+		t.Error("LoadGTFS() should fail for a missing feed")
+	}
+}
+
+// TestRouter_Travel tests earliest arrival routing including walking and transfers
+func TestRouter_Travel(t *testing.T) {
+	eight := 8 * 3600
+
+	tests := []struct {
+		name        string
+		weekday     time.Weekday
+		from, to    geo.Point
+		departure   int
+		wantMinutes int
+		wantOk      bool
+	}{
+		{
+			name:        "change with a walk between platforms",
+			weekday:     time.Tuesday,
+			from:        stationA,
+			to:          stationC,
+			departure:   eight,
+			wantMinutes: 30,
+			wantOk:      true,
+		},
+		{
+			name:        "first train missed",
+			weekday:     time.Tuesday,
+			from:        stationA,
+			to:          stationC,
+			departure:   eight + 6*60,
+			wantMinutes: 44,
+			wantOk:      true,
+		},
+		{
+			name:        "walk to the station",
+			weekday:     time.Tuesday,
+			from:        geo.Point{Lat: 52.5045, Lon: 13.3000},
+			to:          stationC,
+			departure:   eight,
+			wantMinutes: 50,
+			wantOk:      true,
+		},
+		{
+			name:        "service of another weekday",
+			weekday:     time.Sunday,
+			from:        stationA,
+			to:          stationC,
+			departure:   eight,
+			wantMinutes: 11,
+			wantOk:      true,
+		},
+		{
+			name:        "destination within walking distance",
+			weekday:     time.Tuesday,
+			from:        geo.Point{Lat: 52.4955, Lon: 13.5000},
+			to:          stationC,
+			departure:   eight,
+			wantMinutes: 9,
+			wantOk:      true,
+		},
+		{
+			name:      "no more connections",
+			weekday:   time.Tuesday,
+			from:      stationA,
+			to:        stationC,
+			departure: eight + 3600,
+			wantOk:    false,
+		},
+		{
+			name:      "origin far from any stop",
+			weekday:   time.Tuesday,
+			from:      geo.Point{Lat: 52.40, Lon: 13.30},
+			to:        stationC,
+			departure: eight,
+			wantOk:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := loadTestRouter(t, tt.weekday)
+			minutes, ok := router.Travel(tt.from, tt.to, tt.departure)
+			if ok != tt.wantOk || minutes != tt.wantMinutes {
+				t.Errorf("Travel() = %d, %v, want %d, %v", minutes, ok, tt.wantMinutes, tt.wantOk)
+			}
+		})
+	}
+}
+
+// TestEstimator_Estimate tests commute estimates to several destinations
+func TestEstimator_Estimate(t *testing.T) {
+	unreachable := geo.Point{Lat: 52.40, Lon: 13.60}
+	estimator, err := NewEstimator(loadTestRouter(t, time.Tuesday), "08:00", []geo.Point{stationC, unreachable})
+	if err != nil {
+		t.Fatalf("NewEstimator() error = %v", err)
+	}
+
+	minutes := estimator.Estimate(stationA)
+	if len(minutes) != 1 || minutes[stationC] != 30 {
+		t.Errorf("Estimate() = %v, want only 30 minutes to station C", minutes)
+	}
+	if again := estimator.Estimate(stationA); again[stationC] != 30 {
+		t.Errorf("cached Estimate() = %v", again)
+	}
+
+	if _, err := NewEstimator(nil, "8 Uhr", nil); err == nil {
+		t.Error("NewEstimator() should reject an invalid departure")
+	}
+}
@@ -1,31 +1,184 @@
 package users
 
-import "apartmenthunter/internal/config"
+import (
+	"apartmenthunter/internal/config"
+	"apartmenthunter/internal/geo"
+	"apartmenthunter/internal/wbs"
+	"log"
+	"time"
+)
 
 type UserConfig struct {
-	UserID      string
-	ZipCodes    []string
-	WbsRequired bool
-	MinSqm      int
-	MaxSqm      int
-	MinPrice    int
-	MaxPrice    int
+	UserID         string
+	ChatID         string // telegram chat for this user, empty uses the default chat
+	ZipCodes       []string
+	Districts      []string     // Bezirk or Ortsteil names, a listing matches if its zip code or district does
+	Radius         *Radius      // nil disables, listings that could not be geocoded never match
+	Area           *geo.Polygon // nil disables, listings that could not be geocoded never match
+	Commutes       []Commute
+	WbsRequired    bool             // only listings that require a WBS
+	Wbs            *wbs.Entitlement // nil if the user has no WBS, listings requiring one never match
+	MinSqm         int
+	MaxSqm         int
+	MinPrice       int
+	MaxPrice       int         // MinPrice and MaxPrice bound the warm rent
+	MaxWarmPerSqm  float64     // 0 disables
+	QuietHours     *QuietHours // nil disables quiet hours
+	UrgentMaxPrice int         // matches at or below this rent are sent during quiet hours, 0 disables
+	Tolerance      *Tolerance  // nil disables near match notifications
+	Preferences    *Preferences
+
+	// keyword filters on listing title and description, matched case-insensitively with umlauts
+	// spelled out ("für" == "fuer"). Keywords match at the start of a word, exclude keywords also
+	// at its end ("Wohnungstausch"). If any include entry is set, at least one has to match.
+	IncludeKeywords []string
+	ExcludeKeywords []string
+	IncludePatterns []string // regular expressions
+	ExcludePatterns []string // regular expressions
+}
+
+// Radius limits listings to a circle around a point, e.g. the kids' school
+type Radius struct {
+	Center geo.Point
+	Km     float64
+}
+
+// Commute is a regular destination reached by public transport, e.g. work
+type Commute struct {
+	Name        string
+	Destination geo.Point
+	MaxMinutes  int // 0 only shows the commute without filtering
+}
+
+// Preferences rate the listings that passed the filters above, nil disables scoring
+type Preferences struct {
+	TargetPricePerSqm float64  // €/m² considered a good deal, twice the target scores zero
+	MinRooms          float64  // fewer rooms score proportionally lower
+	Districts         []string // preferred areas, earlier entries score higher
+	MinFloor          int      // lower floors score zero (e.g. 1 to avoid the ground floor)
+	Weights           Weights
+}
+
+// Weights set the relative importance of each scoring criterion, 0 ignores a criterion
+type Weights struct {
+	PricePerSqm float64
+	Size        float64
+	Rooms       float64
+	District    float64
+	Floor       float64
+	Balcony     float64
+}
+
+// Tolerance relaxes the strict filters for "near match" notifications
+type Tolerance struct {
+	PricePercent float64  // rent may exceed MaxPrice by this percentage
+	SqmBelow     int      // size may fall short of MinSqm by this many m²
+	ZipCodes     []string // neighbouring zip codes accepted in addition to ZipCodes
+}
+
+// QuietHours is a daily "HH:MM" window in which non-urgent notifications are held back,
+// the window may wrap around midnight (e.g. 23:00 - 07:00)
+type QuietHours struct {
+	Start string
+	End   string
 }
 
 type FilterConfig struct {
 	Users []UserConfig
 }
 
+// Contains reports whether the wall clock time of t falls inside the quiet hours
+func (q *QuietHours) Contains(t time.Time) bool {
+	start, ok := minuteOfDay(q.Start)
+	if !ok {
+		return false
+	}
+	end, ok := minuteOfDay(q.End)
+	if !ok || start == end {
+		return false
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+func minuteOfDay(hhmm string) (int, bool) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
 func LoadFromStaticConfig() *FilterConfig {
-	return &FilterConfig{Users: []UserConfig{
+	filterConfig := &FilterConfig{Users: []UserConfig{
 		{
-			UserID:      "",
-			ZipCodes:    config.ZipCodes,
-			WbsRequired: config.Wbs,
-			MinSqm:      config.MinSqm,
-			MaxSqm:      config.MaxSqm,
-			MinPrice:    config.MinWarm,
-			MaxPrice:    config.MaxWarm,
+			UserID:         "",
+			ZipCodes:       config.ZipCodes,
+			Districts:      config.Districts,
+			WbsRequired:    config.Wbs,
+			MinSqm:         config.MinSqm,
+			MaxSqm:         config.MaxSqm,
+			MinPrice:       config.MinWarm,
+			MaxPrice:       config.MaxWarm,
+			MaxWarmPerSqm:  config.MaxWarmPerSqm,
+			UrgentMaxPrice: config.UrgentMaxWarm,
+			Preferences: &Preferences{
+				TargetPricePerSqm: config.TargetPricePerSqm,
+				MinRooms:          config.MinRooms,
+				Districts:         config.PreferredDistricts,
+				MinFloor:          config.MinFloor,
+				Weights: Weights{
+					PricePerSqm: 3,
+					Size:        2,
+					Rooms:       2,
+					District:    2,
+					Floor:       1,
+					Balcony:     1,
+				},
+			},
+			ExcludeKeywords: config.ExcludeKeywords,
+			ExcludePatterns: config.ExcludePatterns,
 		},
 	}}
+
+	user := &filterConfig.Users[0]
+	if config.QuietHoursStart != "" && config.QuietHoursEnd != "" {
+		user.QuietHours = &QuietHours{Start: config.QuietHoursStart, End: config.QuietHoursEnd}
+	}
+	if config.PriceTolerancePercent > 0 || config.SqmTolerance > 0 || len(config.NeighbourZipCodes) > 0 {
+		user.Tolerance = &Tolerance{
+			PricePercent: config.PriceTolerancePercent,
+			SqmBelow:     config.SqmTolerance,
+			ZipCodes:     config.NeighbourZipCodes,
+		}
+	}
+	if config.SearchRadiusKm > 0 {
+		user.Radius = &Radius{
+			Center: geo.Point{Lat: config.SearchCenterLat, Lon: config.SearchCenterLon},
+			Km:     config.SearchRadiusKm,
+		}
+	}
+	if config.HasWbs {
+		user.Wbs = &wbs.Entitlement{Level: config.WbsLevel, SpecialNeeds: config.WbsSpecialNeeds}
+	}
+	if config.CommuteName != "" {
+		user.Commutes = []Commute{{
+			Name:        config.CommuteName,
+			Destination: geo.Point{Lat: config.CommuteLat, Lon: config.CommuteLon},
+			MaxMinutes:  config.CommuteMaxMinutes,
+		}}
+	}
+	if config.SearchAreaFile != "" {
+		area, err := geo.LoadPolygon(config.SearchAreaFile)
+		if err != nil {
+			log.Printf("search area %s not loaded, area filter disabled: %v", config.SearchAreaFile, err)
+		} else {
+			user.Area = area
+		}
+	}
+	return filterConfig
 }
@@ -0,0 +1,38 @@
+package users
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHours_Contains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 19, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		quiet    QuietHours
+		time     time.Time
+		expected bool
+	}{
+		{"overnight window, late evening", QuietHours{"23:00", "07:00"}, at(23, 30), true},
+		{"overnight window, early morning", QuietHours{"23:00", "07:00"}, at(3, 0), true},
+		{"overnight window, at start", QuietHours{"23:00", "07:00"}, at(23, 0), true},
+		{"overnight window, at end", QuietHours{"23:00", "07:00"}, at(7, 0), false},
+		{"overnight window, daytime", QuietHours{"23:00", "07:00"}, at(12, 0), false},
+		{"same day window, inside", QuietHours{"13:00", "15:00"}, at(14, 59), true},
+		{"same day window, outside", QuietHours{"13:00", "15:00"}, at(15, 1), false},
+		{"empty window", QuietHours{"10:00", "10:00"}, at(10, 0), false},
+		{"invalid start", QuietHours{"late", "07:00"}, at(3, 0), false},
+		{"invalid end", QuietHours{"23:00", ""}, at(23, 30), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quiet.Contains(tt.time); got != tt.expected {
+				t.Errorf("Contains(%s) = %v, want %v", tt.time.Format("15:04"), got, tt.expected)
+			}
+		})
+	}
+}
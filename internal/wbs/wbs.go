@@ -0,0 +1,111 @@
+// Package wbs parses the Wohnberechtigungsschein (WBS) requirements of listings and
+// checks them against a user's entitlement.
+package wbs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// phrasings stating that no WBS is needed, checked before anything else
+var negatives = []string{
+	"ohne wbs",
+	"ohne wohnberechtigungsschein",
+	"wbs-frei", "wbs frei",
+	"o. wbs",
+	"kein wbs", "keine wbs",
+	"wbs nicht erforderlich",
+	"ohne wohnberechtigung",
+}
+
+var positives = []string{
+	"wohnberechtigungsschein",
+	"nur mit wbs", "mit wbs",
+	"wbs pflicht", "wbs erforderlich",
+	"wbs",
+}
+
+var specialNeeds = []string{
+	"besonderer wohnbedarf", "besonderem wohnbedarf", "besonderen wohnbedarf",
+}
+
+// levelPattern matches income levels like "WBS 140", "WBS-160" or "WBS 100/140"
+var levelPattern = regexp.MustCompile(`wbs[\s\-_:]*(\d{3})\b((?:\s*(?:/|-|bis|oder|und|,)\s*(?:wbs[\s\-]*)?\d{3}\b)*)`)
+
+var digits = regexp.MustCompile(`\d{3}`)
+
+// Requirement is the WBS a listing asks for
+type Requirement struct {
+	Required     bool
+	Level        int  // highest accepted income level in percent, e.g. 140 for "WBS 140"; 0 accepts any
+	SpecialNeeds bool // "WBS mit besonderem Wohnbedarf"
+}
+
+// Entitlement is the WBS a user holds
+type Entitlement struct {
+	Level        int // income level on the certificate, 0 if unknown
+	SpecialNeeds bool
+}
+
+// Parse reads the WBS requirement from listing text such as a title or description
+func Parse(text string) Requirement {
+	t := strings.ToLower(text)
+	for _, n := range negatives {
+		if strings.Contains(t, n) {
+			return Requirement{}
+		}
+	}
+
+	var r Requirement
+	for _, s := range specialNeeds {
+		if strings.Contains(t, s) {
+			r.Required, r.SpecialNeeds = true, true
+		}
+	}
+	for _, match := range levelPattern.FindAllStringSubmatch(t, -1) {
+		r.Required = true
+		for _, level := range digits.FindAllString(match[1]+match[2], -1) {
+			if n, _ := strconv.Atoi(level); n > r.Level {
+				r.Level = n
+			}
+		}
+	}
+	for _, p := range positives {
+		if strings.Contains(t, p) {
+			r.Required = true
+		}
+	}
+	return r
+}
+
+// Allows reports whether the holder of the entitlement may rent a listing with the
+// requirement. A nil entitlement (no WBS) only allows listings without one. A WBS for a
+// lower income level covers higher listing levels, e.g. WBS 100 covers WBS 140 flats.
+func (e *Entitlement) Allows(r Requirement) bool {
+	if !r.Required {
+		return true
+	}
+	if e == nil {
+		return false
+	}
+	if r.SpecialNeeds && !e.SpecialNeeds {
+		return false
+	}
+	return r.Level == 0 || e.Level == 0 || e.Level <= r.Level
+}
+
+func (r Requirement) String() string {
+	if !r.Required {
+		return "no WBS"
+	}
+	s := "WBS"
+	if r.Level > 0 {
+		s = fmt.Sprintf("WBS %d", r.Level)
+	}
+	if r.SpecialNeeds {
+		s += " (besonderer Wohnbedarf)"
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package wbs
+
+import "testing"
+
+// TestParse tests reading WBS requirements from listing text
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected Requirement
+	}{
+		{"no wbs mentioned", "Schöne 2-Zimmer-Wohnung", Requirement{}},
+		{"without wbs", "Wohnung ohne WBS", Requirement{}},
+		{"wbs free", "WBS-frei ab sofort", Requirement{}},
+		{"any wbs", "Nur mit WBS", Requirement{Required: true}},
+		{"full word", "Wohnberechtigungsschein erforderlich", Requirement{Required: true}},
+		{"income level", "2-Zimmer, WBS 140 erforderlich", Requirement{Required: true, Level: 140}},
+		{"income level with hyphen", "WBS-160", Requirement{Required: true, Level: 160}},
+		{"income level range", "WBS 100/140", Requirement{Required: true, Level: 140}},
+		{"income level range with words", "WBS 140 bis WBS 180", Requirement{Required: true, Level: 180}},
+		{"percent sign", "wbs 180%", Requirement{Required: true, Level: 180}},
+		{"zip code is not a level", "WBS 12043 Berlin", Requirement{Required: true}},
+		{"special needs", "WBS mit besonderem Wohnbedarf", Requirement{Required: true, SpecialNeeds: true}},
+		{"special needs without wbs", "für Menschen mit besonderem Wohnbedarf", Requirement{Required: true, SpecialNeeds: true}},
+		{"special needs with level", "WBS 100 mit besonderem Wohnbedarf", Requirement{Required: true, Level: 100, SpecialNeeds: true}},
+		{"empty", "", Requirement{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Parse(tt.text); result != tt.expected {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.text, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEntitlement_Allows tests matching entitlements against requirements in both directions
+func TestEntitlement_Allows(t *testing.T) {
+	tests := []struct {
+		name        string
+		entitlement *Entitlement
+		requirement Requirement
+		expected    bool
+	}{
+		{"no wbs, listing without wbs", nil, Requirement{}, true},
+		{"no wbs, listing with wbs", nil, Requirement{Required: true}, false},
+		{"wbs, listing without wbs", &Entitlement{Level: 140}, Requirement{}, true},
+		{"wbs, any wbs accepted", &Entitlement{Level: 180}, Requirement{Required: true}, true},
+		{"same level", &Entitlement{Level: 140}, Requirement{Required: true, Level: 140}, true},
+		{"lower income covers higher level", &Entitlement{Level: 100}, Requirement{Required: true, Level: 160}, true},
+		{"higher income than listing allows", &Entitlement{Level: 180}, Requirement{Required: true, Level: 140}, false},
+		{"unknown level", &Entitlement{}, Requirement{Required: true, Level: 140}, true},
+		{"special needs required", &Entitlement{Level: 100}, Requirement{Required: true, SpecialNeeds: true}, false},
+		{"special needs held", &Entitlement{Level: 100, SpecialNeeds: true}, Requirement{Required: true, SpecialNeeds: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.entitlement.Allows(tt.requirement); result != tt.expected {
+				t.Errorf("Allows(%+v) = %v, want %v", tt.requirement, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRequirement_String tests the human readable requirement
+func TestRequirement_String(t *testing.T) {
+	tests := []struct {
+		requirement Requirement
+		expected    string
+	}{
+		{Requirement{}, "no WBS"},
+		{Requirement{Required: true}, "WBS"},
+		{Requirement{Required: true, Level: 140}, "WBS 140"},
+		{Requirement{Required: true, Level: 100, SpecialNeeds: true}, "WBS 100 (besonderer Wohnbedarf)"},
+	}
+
+	for _, tt := range tests {
+		if result := tt.requirement.String(); result != tt.expected {
+			t.Errorf("String() = %q, want %q", result, tt.expected)
+		}
+	}
+}